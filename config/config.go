@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/joho/godotenv"
 
 	kenv "github.com/knadh/koanf/providers/env"
@@ -14,6 +16,71 @@ type (
 		SmtpAuthEmail    string `koanf:"SMTP_AUTH_EMAIL"`
 		SmtpAuthPassword string `koanf:"SMTP_AUTH_PASSWORD"`
 		DBFile           string `koanf:"DB_FILE"`
+
+		// SmtpFrom is the sender of emails, e.g.,
+		// "Scheduler <scheduler@example.com>", defaults to the SMTP account
+		SmtpFrom string `koanf:"SMTP_FROM"`
+		// SmtpTLS is "auto" (default, STARTTLS when the server offers it),
+		// "starttls", "tls" for implicit TLS or "none". SmtpCAFile holds PEM
+		// certificates trusted besides the system ones, SmtpTLSSkipVerify
+		// accepts any certificate, for development only.
+		SmtpTLS           string `koanf:"SMTP_TLS"`
+		SmtpTLSSkipVerify bool   `koanf:"SMTP_TLS_SKIP_VERIFY"`
+		SmtpCAFile        string `koanf:"SMTP_CA_FILE"`
+		// SmtpAuth is the auth mechanism, "plain", "login", "cram-md5" or
+		// "none", defaults to "plain" with an SMTP account and "none" without
+		SmtpAuth string `koanf:"SMTP_AUTH"`
+		// SmtpPoolSize connections to the SMTP server are kept open for
+		// SmtpIdleTimeout after sending an email
+		SmtpPoolSize    int           `koanf:"SMTP_POOL_SIZE"`
+		SmtpIdleTimeout time.Duration `koanf:"SMTP_IDLE_TIMEOUT"`
+		// SmtpCommandTimeout bounds every read and write of an SMTP
+		// conversation, a stalled server fails the email instead of blocking
+		// its sender
+		SmtpCommandTimeout time.Duration `koanf:"SMTP_COMMAND_TIMEOUT"`
+
+		// DevMail sends emails to an SMTP sink listening on localhost at
+		// DevMailPort instead of the SMTP server, they are shown at /dev/mail.
+		// For development and tests only.
+		DevMail     bool `koanf:"DEV_MAIL"`
+		DevMailPort int  `koanf:"DEV_MAIL_PORT"`
+
+		// CalendarDir holds the holiday calendars, as .ics or .yaml files
+		CalendarDir string `koanf:"CALENDAR_DIR"`
+		// EmailTemplateDir holds the email templates, see emailtemplate.LoadDir
+		EmailTemplateDir string `koanf:"EMAIL_TEMPLATE_DIR"`
+		// EmailAttachICS attaches the occurrence of a reminder to its emails
+		// as an iCalendar event
+		EmailAttachICS bool `koanf:"EMAIL_ATTACH_ICS"`
+
+		DispatchInterval time.Duration `koanf:"DISPATCH_INTERVAL"`
+		// MisfireThreshold is the lag after which a due schedule is handled
+		// by the misfire policy of its reminder
+		MisfireThreshold time.Duration `koanf:"MISFIRE_THRESHOLD"`
+		// OutboxRelayInterval is how often the relay looks for outbox entries
+		// to retry, new entries are delivered as soon as they are written
+		OutboxRelayInterval time.Duration `koanf:"OUTBOX_RELAY_INTERVAL"`
+
+		// BaseURL is the address of the server in the links of notifications,
+		// ActionSecret signs the links, a generated one is kept in the database
+		// when it is empty. Links expire after ActionLinkTTL
+		BaseURL       string        `koanf:"BASE_URL"`
+		ActionSecret  string        `koanf:"ACTION_SECRET"`
+		ActionLinkTTL time.Duration `koanf:"ACTION_LINK_TTL"`
+
+		// QuietHoursStart and QuietHoursEnd are the wall clocks of the global
+		// quiet hours, e.g., "22:00" and "07:00", in QuietHoursTimezone.
+		// QuietHoursPolicy is "defer" (default) or "drop". There are no global
+		// quiet hours when both are empty.
+		QuietHoursStart    string `koanf:"QUIET_HOURS_START"`
+		QuietHoursEnd      string `koanf:"QUIET_HOURS_END"`
+		QuietHoursTimezone string `koanf:"QUIET_HOURS_TIMEZONE"`
+		QuietHoursPolicy   string `koanf:"QUIET_HOURS_POLICY"`
+
+		RetryMaxAttempts int           `koanf:"RETRY_MAX_ATTEMPTS"`
+		RetryBaseDelay   time.Duration `koanf:"RETRY_BASE_DELAY"`
+		RetryMaxDelay    time.Duration `koanf:"RETRY_MAX_DELAY"`
+		RetryJitter      float64       `koanf:"RETRY_JITTER"`
 	}
 )
 
@@ -36,5 +103,62 @@ func LoadConfig() (*Config, error) {
 		config.DBFile = "scheduler.db"
 	}
 
+	if config.SmtpPoolSize <= 0 {
+		config.SmtpPoolSize = 2
+	}
+
+	if config.SmtpIdleTimeout <= 0 {
+		config.SmtpIdleTimeout = 30 * time.Second
+	}
+
+	if config.SmtpCommandTimeout <= 0 {
+		config.SmtpCommandTimeout = time.Minute
+	}
+
+	if config.DevMailPort <= 0 {
+		config.DevMailPort = 1025
+	}
+
+	if config.CalendarDir == "" {
+		config.CalendarDir = "calendars"
+	}
+
+	if config.EmailTemplateDir == "" {
+		config.EmailTemplateDir = "templates/emails"
+	}
+
+	if config.DispatchInterval <= 0 {
+		config.DispatchInterval = 30 * time.Second
+	}
+
+	if config.MisfireThreshold <= 0 {
+		// a schedule due right after a tick waits one interval
+		config.MisfireThreshold = 2 * config.DispatchInterval
+	}
+
+	if config.OutboxRelayInterval <= 0 {
+		config.OutboxRelayInterval = 5 * time.Second
+	}
+
+	if config.BaseURL == "" {
+		config.BaseURL = "http://localhost:8080"
+	}
+
+	if config.ActionLinkTTL <= 0 {
+		config.ActionLinkTTL = 7 * 24 * time.Hour
+	}
+
+	if config.RetryMaxAttempts <= 0 {
+		config.RetryMaxAttempts = 5
+	}
+
+	if config.RetryBaseDelay <= 0 {
+		config.RetryBaseDelay = 30 * time.Second
+	}
+
+	if config.RetryMaxDelay <= 0 {
+		config.RetryMaxDelay = time.Hour
+	}
+
 	return &config, nil
 }
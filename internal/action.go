@@ -0,0 +1,157 @@
+package internal
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// ScheduleOccurrence is a schedule materialized from an occurrence of
+	// its reminder
+	ScheduleOccurrence = "occurrence"
+	// ScheduleSnooze is a schedule created by snoozing a fired schedule, its
+	// ParentID is the snoozed schedule
+	ScheduleSnooze = "snooze"
+
+	// ResponseAcknowledged marks a fired schedule its recipient acknowledged
+	ResponseAcknowledged = "acknowledged"
+	// ResponseSnoozed marks a fired schedule its recipient snoozed
+	ResponseSnoozed = "snoozed"
+
+	ActionAcknowledge = "acknowledge"
+	ActionSnooze      = "snooze"
+)
+
+// Acknowledge records that the recipient of the fired schedule saw it.
+// Acknowledging an acknowledged schedule does nothing, one-click links may be
+// opened more than once.
+func (s *Schedule) Acknowledge(now time.Time) error {
+	if err := s.respondable(); err != nil {
+		return err
+	}
+	if s.Response == ResponseAcknowledged {
+		return nil
+	}
+
+	s.Response = ResponseAcknowledged
+	s.RespondedAt = now
+	s.IsDone = true
+	return nil
+}
+
+// Snooze marks the fired schedule done and returns the schedule firing it
+// again at until
+func (s *Schedule) Snooze(until, now time.Time) (*Schedule, error) {
+	if err := s.respondable(); err != nil {
+		return nil, err
+	}
+	if s.Response == ResponseAcknowledged {
+		return nil, fmt.Errorf("schedule %d is already acknowledged", s.ID)
+	}
+	if s.Step > 0 {
+		return nil, fmt.Errorf("schedule %d is an escalation, escalations can only be acknowledged", s.ID)
+	}
+	if !until.After(now) {
+		return nil, fmt.Errorf("snooze time must be in the future")
+	}
+
+	s.Response = ResponseSnoozed
+	s.RespondedAt = now
+	s.IsDone = true
+
+	snooze := NewSchedule(s.TaskID, s.ReminderID, until)
+	snooze.Kind = ScheduleSnooze
+	snooze.ParentID = s.ID
+	// the snooze fires the occurrence of the snoozed schedule again, to the
+	// same recipients
+	snooze.OccurrenceAt = s.OccurrenceAt
+	snooze.Recipients = s.Recipients
+	return snooze, nil
+}
+
+// respondable checks that the recipient of the schedule can respond to it,
+// only delivered schedules that were not snoozed can be responded to
+func (s *Schedule) respondable() error {
+	if s.Status != StatusSuccess {
+		return fmt.Errorf("schedule %d is %s, only delivered schedules can be responded to", s.ID, s.Status)
+	}
+	if s.Response == ResponseSnoozed {
+		return fmt.Errorf("schedule %d is already snoozed", s.ID)
+	}
+	return nil
+}
+
+// ActionLinks builds the signed one-click links recipients use to respond to
+// a fired schedule, and verifies them. A link expires ttl after it is built.
+type ActionLinks struct {
+	baseURL string
+	secret  []byte
+	ttl     time.Duration
+	clock   Clock
+}
+
+func NewActionLinks(baseURL, secret string, ttl time.Duration, clock Clock) *ActionLinks {
+	return &ActionLinks{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		secret:  []byte(secret),
+		ttl:     ttl,
+		clock:   clock,
+	}
+}
+
+// AcknowledgeURL returns the link acknowledging the schedule
+func (l *ActionLinks) AcknowledgeURL(scheduleID int64) string {
+	return l.link(ActionAcknowledge, scheduleID, url.Values{})
+}
+
+// SnoozeURL returns the link snoozing the schedule for d
+func (l *ActionLinks) SnoozeURL(scheduleID int64, d time.Duration) string {
+	return l.link(ActionSnooze, scheduleID, url.Values{"duration": {d.String()}})
+}
+
+func (l *ActionLinks) link(action string, scheduleID int64, query url.Values) string {
+	query.Set("expires", strconv.FormatInt(l.clock.Now().Add(l.ttl).Unix(), 10))
+	query.Set("token", l.sign(action, scheduleID, query))
+	return fmt.Sprintf("%s/schedules/%d/%s?%s", l.baseURL, scheduleID, action, query.Encode())
+}
+
+// Verify checks the query of a one-click link of the action on the schedule
+func (l *ActionLinks) Verify(action string, scheduleID int64, query url.Values) error {
+	token, err := hex.DecodeString(query.Get("token"))
+	if err != nil || !hmac.Equal(token, l.mac(action, scheduleID, query)) {
+		return fmt.Errorf("invalid link signature")
+	}
+
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil || l.clock.Now().After(time.Unix(expires, 0)) {
+		return fmt.Errorf("link expired")
+	}
+
+	return nil
+}
+
+func (l *ActionLinks) sign(action string, scheduleID int64, query url.Values) string {
+	return hex.EncodeToString(l.mac(action, scheduleID, query))
+}
+
+// mac authenticates the action, the schedule and every query parameter of a
+// link but its token
+func (l *ActionLinks) mac(action string, scheduleID int64, query url.Values) []byte {
+	signed := url.Values{}
+	for key, values := range query {
+		if key != "token" {
+			signed[key] = values
+		}
+	}
+
+	mac := hmac.New(sha256.New, l.secret)
+	// Encode sorts the parameters by key
+	fmt.Fprintf(mac, "%s\n%d\n%s", action, scheduleID, signed.Encode())
+	return mac.Sum(nil)
+}
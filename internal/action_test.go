@@ -0,0 +1,157 @@
+package internal
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSchedule_Acknowledge(t *testing.T) {
+	now := time.Date(2025, 7, 20, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		status   ActionStatus
+		response string
+		wantErr  bool
+	}{
+		{name: "delivered", status: StatusSuccess},
+		{name: "acknowledged twice", status: StatusSuccess, response: ResponseAcknowledged},
+		{name: "snoozed", status: StatusSuccess, response: ResponseSnoozed, wantErr: true},
+		{name: "not fired", status: StatusCreated, wantErr: true},
+		{name: "failed", status: StatusDead, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := NewSchedule(1, 1, now.Add(-time.Hour))
+			schedule.Status = tt.status
+			schedule.Response = tt.response
+			schedule.IsDone = tt.response != ""
+
+			err := schedule.Acknowledge(now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Schedule.Acknowledge() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (schedule.Response != ResponseAcknowledged || !schedule.IsDone) {
+				t.Errorf("Schedule.Acknowledge() response = %q, done = %v", schedule.Response, schedule.IsDone)
+			}
+		})
+	}
+}
+
+func TestSchedule_Snooze(t *testing.T) {
+	now := time.Date(2025, 7, 20, 9, 0, 0, 0, time.UTC)
+	occurrence := now.Add(-2 * time.Hour)
+	tests := []struct {
+		name     string
+		status   ActionStatus
+		response string
+		until    time.Time
+		wantErr  bool
+	}{
+		{name: "delivered", status: StatusSuccess, until: now.Add(time.Hour)},
+		{name: "until now", status: StatusSuccess, until: now, wantErr: true},
+		{name: "acknowledged", status: StatusSuccess, response: ResponseAcknowledged, until: now.Add(time.Hour), wantErr: true},
+		{name: "snoozed", status: StatusSuccess, response: ResponseSnoozed, until: now.Add(time.Hour), wantErr: true},
+		{name: "retried", status: StatusFailed, until: now.Add(time.Hour), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := NewSchedule(1, 2, occurrence.Add(time.Minute))
+			schedule.ID = 3
+			schedule.OccurrenceAt = occurrence
+			schedule.Status = tt.status
+			schedule.Response = tt.response
+
+			snooze, err := schedule.Snooze(tt.until, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Schedule.Snooze() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if schedule.Response != ResponseSnoozed || !schedule.RespondedAt.Equal(now) || !schedule.IsDone {
+				t.Errorf("snoozed schedule response = %q at %v, done = %v", schedule.Response, schedule.RespondedAt, schedule.IsDone)
+			}
+			want := Schedule{
+				TaskID:       1,
+				ReminderID:   2,
+				Status:       StatusCreated,
+				NotifyAt:     tt.until,
+				OccurrenceAt: occurrence,
+				Kind:         ScheduleSnooze,
+				ParentID:     3,
+			}
+			if !reflect.DeepEqual(*snooze, want) {
+				t.Errorf("Schedule.Snooze() = %+v, want %+v", *snooze, want)
+			}
+		})
+	}
+}
+
+func TestActionLinks(t *testing.T) {
+	clock := NewFakeClock(time.Date(2025, 7, 20, 9, 0, 0, 0, time.UTC))
+	links := NewActionLinks("http://scheduler.test/", "secret", time.Hour, clock)
+
+	query := func(t *testing.T, link string) url.Values {
+		t.Helper()
+		u, err := url.Parse(link)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u.Query()
+	}
+
+	t.Run("acknowledge", func(t *testing.T) {
+		link := links.AcknowledgeURL(42)
+		if !strings.HasPrefix(link, "http://scheduler.test/schedules/42/acknowledge?") {
+			t.Errorf("ActionLinks.AcknowledgeURL() = %s", link)
+		}
+		if err := links.Verify(ActionAcknowledge, 42, query(t, link)); err != nil {
+			t.Errorf("ActionLinks.Verify() error = %v", err)
+		}
+		if err := links.Verify(ActionAcknowledge, 43, query(t, link)); err == nil {
+			t.Errorf("ActionLinks.Verify() accepted the link of another schedule")
+		}
+		if err := links.Verify(ActionSnooze, 42, query(t, link)); err == nil {
+			t.Errorf("ActionLinks.Verify() accepted the link of another action")
+		}
+	})
+
+	t.Run("snooze", func(t *testing.T) {
+		link := links.SnoozeURL(42, 15*time.Minute)
+		values := query(t, link)
+		if values.Get("duration") != "15m0s" {
+			t.Errorf("ActionLinks.SnoozeURL() duration = %q", values.Get("duration"))
+		}
+		if err := links.Verify(ActionSnooze, 42, values); err != nil {
+			t.Errorf("ActionLinks.Verify() error = %v", err)
+		}
+
+		values.Set("duration", "24h")
+		if err := links.Verify(ActionSnooze, 42, values); err == nil {
+			t.Errorf("ActionLinks.Verify() accepted a tampered duration")
+		}
+	})
+
+	t.Run("other secret", func(t *testing.T) {
+		other := NewActionLinks("http://scheduler.test", "other", time.Hour, clock)
+		if err := links.Verify(ActionAcknowledge, 42, query(t, other.AcknowledgeURL(42))); err == nil {
+			t.Errorf("ActionLinks.Verify() accepted a link signed with another secret")
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		values := query(t, links.AcknowledgeURL(42))
+		clock.Advance(time.Hour)
+		if err := links.Verify(ActionAcknowledge, 42, values); err != nil {
+			t.Errorf("ActionLinks.Verify() error = %v at expiry", err)
+		}
+		clock.Advance(time.Second)
+		if err := links.Verify(ActionAcknowledge, 42, values); err == nil {
+			t.Errorf("ActionLinks.Verify() accepted an expired link")
+		}
+	})
+}
@@ -0,0 +1,141 @@
+package internal
+
+import (
+	"slices"
+	"time"
+)
+
+const (
+	// HolidaySkip drops runs falling outside business days
+	HolidaySkip = "skip"
+	// HolidayPreviousBusinessDay moves runs falling outside business days to
+	// the previous business day, at the same wall clock. A moved run landing
+	// on another run is merged into it.
+	HolidayPreviousBusinessDay = "previous_business_day"
+	// HolidayNextBusinessDay moves runs falling outside business days to the
+	// next business day, at the same wall clock. A moved run landing on
+	// another run is merged into it.
+	HolidayNextBusinessDay = "next_business_day"
+
+	// maxHolidayShift bounds the days searched for a business day
+	maxHolidayShift = 366
+)
+
+// Calendar is a named set of holidays. Business days are the days that are
+// neither a weekend day nor a holiday.
+type Calendar struct {
+	Name     string            `json:"name"`
+	Weekend  []time.Weekday    `json:"weekend"`
+	Holidays map[string]string `json:"holidays"` // holiday names keyed by date, e.g., "2025-12-25"
+}
+
+// NewCalendar creates a calendar, a nil weekend defaults to Saturday and
+// Sunday
+func NewCalendar(name string, weekend []time.Weekday, holidays map[string]string) *Calendar {
+	if weekend == nil {
+		weekend = []time.Weekday{time.Saturday, time.Sunday}
+	}
+	if holidays == nil {
+		holidays = make(map[string]string)
+	}
+
+	return &Calendar{
+		Name:     name,
+		Weekend:  weekend,
+		Holidays: holidays,
+	}
+}
+
+// IsBusinessDay reports whether the date of t, in the location of t, is a
+// business day
+func (c *Calendar) IsBusinessDay(t time.Time) bool {
+	if slices.Contains(c.Weekend, t.Weekday()) {
+		return false
+	}
+
+	_, holiday := c.Holidays[t.Format(time.DateOnly)]
+	return !holiday
+}
+
+// SetCalendar attaches the holiday calendar named by the Calendar field of the
+// Reminder. Calendars are loaded apart from reminders, a Reminder without an
+// attached calendar runs on holidays.
+func (s *Reminder) SetCalendar(calendar *Calendar) {
+	s.calendar = calendar
+}
+
+// offBusinessDay reports whether the run at t falls outside the business days
+// of the calendar of the Reminder
+func (s *Reminder) offBusinessDay(t time.Time) bool {
+	return s.calendar != nil && !s.calendar.IsBusinessDay(t.In(s.Location()))
+}
+
+// holidaySkipped reports whether the run at t is dropped by the holiday policy
+func (s *Reminder) holidaySkipped(t time.Time) bool {
+	return (s.HolidayPolicy == "" || s.HolidayPolicy == HolidaySkip) && s.offBusinessDay(t)
+}
+
+// holidayShift moves the run at t to a business day when the holiday policy
+// says so. The moved run keeps its wall clock, runs moved onto another run are
+// dropped by holidayMerged.
+func (s *Reminder) holidayShift(t time.Time) time.Time {
+	step := 0
+	switch s.HolidayPolicy {
+	case HolidayPreviousBusinessDay:
+		step = -1
+	case HolidayNextBusinessDay:
+		step = 1
+	}
+	if step == 0 || !s.offBusinessDay(t) {
+		return t
+	}
+
+	loc := s.Location()
+	wall := floating(t.In(loc))
+	for i := 1; i <= maxHolidayShift; i++ {
+		shifted := wallClock(wall.AddDate(0, 0, i*step), loc)
+		if s.calendar.IsBusinessDay(shifted) {
+			return shifted
+		}
+	}
+
+	return t
+}
+
+// holidayMerged reports whether the run at t is moved by the holiday policy
+// onto another run of the Reminder: the run of the business day it moves to,
+// or the run at the same wall clock of an earlier day it moves with, e.g.,
+// the saturday run for the sunday one. Only the first of the runs landing at
+// the same time fires.
+func (s *Reminder) holidayMerged(t time.Time) bool {
+	if s.HolidayPolicy != HolidayPreviousBusinessDay && s.HolidayPolicy != HolidayNextBusinessDay {
+		return false
+	}
+
+	shifted := s.holidayShift(t)
+	if shifted.Equal(t) {
+		return false
+	}
+	if s.runsAt(shifted) {
+		return true
+	}
+
+	loc := s.Location()
+	wall := floating(t.In(loc))
+	for i := 1; i <= maxHolidayShift; i++ {
+		earlier := wallClock(wall.AddDate(0, 0, -i), loc)
+		if s.calendar.IsBusinessDay(earlier) {
+			break
+		}
+		if s.runsAt(earlier) {
+			return true
+		}
+	}
+	return false
+}
+
+// runsAt reports whether the recurrence of the Reminder has an occurrence at
+// t that is not skipped by a skip exception
+func (s *Reminder) runsAt(t time.Time) bool {
+	return s.seekOccurrence(s.firstOccurrence(), t).Equal(t) && !s.exceptionSkipped(t)
+}
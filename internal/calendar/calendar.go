@@ -0,0 +1,110 @@
+package calendar
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/elangreza/scheduler/internal"
+)
+
+// Registry holds the holiday calendars reminders can reference, keyed by name
+type Registry struct {
+	mu        sync.RWMutex
+	calendars map[string]*internal.Calendar
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		calendars: make(map[string]*internal.Calendar),
+	}
+}
+
+// LoadDir loads every .ics, .yaml and .yml file of dir as a calendar named
+// after the file, e.g., "id.yaml" is the calendar "id". A missing dir loads
+// no calendars.
+func LoadDir(dir string) (*Registry, error) {
+	registry := NewRegistry()
+
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return registry, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+
+		var parse func(name string, data []byte) (*internal.Calendar, error)
+		switch ext {
+		case ".ics":
+			parse = ParseICS
+		case ".yaml", ".yml":
+			parse = ParseYAML
+		default:
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		calendar, err := parse(name, data)
+		if err != nil {
+			return nil, fmt.Errorf("calendar %s: %v", entry.Name(), err)
+		}
+
+		if _, ok := registry.calendars[name]; ok {
+			return nil, fmt.Errorf("calendar %q is defined twice", name)
+		}
+		registry.Register(calendar)
+	}
+
+	return registry, nil
+}
+
+// Register makes a calendar available under its name, replacing any calendar
+// previously registered with that name
+func (r *Registry) Register(calendar *internal.Calendar) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calendars[calendar.Name] = calendar
+}
+
+func (r *Registry) Get(name string) (*internal.Calendar, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	calendar, ok := r.calendars[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown calendar %q", name)
+	}
+
+	return calendar, nil
+}
+
+// List returns the registered calendars sorted by name
+func (r *Registry) List() []internal.Calendar {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	calendars := make([]internal.Calendar, 0, len(r.calendars))
+	for _, calendar := range r.calendars {
+		calendars = append(calendars, *calendar)
+	}
+	sort.Slice(calendars, func(i, j int) bool { return calendars[i].Name < calendars[j].Name })
+
+	return calendars
+}
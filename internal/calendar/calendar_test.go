@@ -0,0 +1,189 @@
+package calendar
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseICS(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         string
+		wantHolidays map[string]string
+		wantErr      bool
+	}{
+		{
+			name: "all day events",
+			data: "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" +
+				"BEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20251225\r\nDTEND;VALUE=DATE:20251226\r\nSUMMARY:Christmas Day\r\nEND:VEVENT\r\n" +
+				"BEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20250331\r\nDTEND;VALUE=DATE:20250402\r\nSUMMARY:Eid al-Fitr\\, days 1 and 2\r\nEND:VEVENT\r\n" +
+				"END:VCALENDAR\r\n",
+			wantHolidays: map[string]string{
+				"2025-12-25": "Christmas Day",
+				"2025-03-31": "Eid al-Fitr, days 1 and 2",
+				"2025-04-01": "Eid al-Fitr, days 1 and 2",
+			},
+		},
+		{
+			name: "folded lines and date time start",
+			data: "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:20250817T000000Z\nSUMMARY:Indepen\n dence Day\nEND:VEVENT\nEND:VCALENDAR\n",
+			wantHolidays: map[string]string{
+				"2025-08-17": "Independence Day",
+			},
+		},
+		{
+			name:    "recurring event",
+			data:    "BEGIN:VEVENT\nDTSTART;VALUE=DATE:20250101\nRRULE:FREQ=YEARLY\nSUMMARY:New Year\nEND:VEVENT\n",
+			wantErr: true,
+		},
+		{
+			name:    "missing start",
+			data:    "BEGIN:VEVENT\nSUMMARY:New Year\nEND:VEVENT\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid start",
+			data:    "BEGIN:VEVENT\nDTSTART;VALUE=DATE:2025-01-01\nEND:VEVENT\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseICS("id", []byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseICS() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got.Holidays, tt.wantHolidays) {
+				t.Errorf("ParseICS() holidays = %v, want %v", got.Holidays, tt.wantHolidays)
+			}
+			if !reflect.DeepEqual(got.Weekend, []time.Weekday{time.Saturday, time.Sunday}) {
+				t.Errorf("ParseICS() weekend = %v", got.Weekend)
+			}
+		})
+	}
+}
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         string
+		wantWeekend  []time.Weekday
+		wantHolidays map[string]string
+		wantErr      bool
+	}{
+		{
+			name:         "default weekend",
+			data:         "holidays:\n  - date: 2025-12-25\n    name: Christmas Day\n",
+			wantWeekend:  []time.Weekday{time.Saturday, time.Sunday},
+			wantHolidays: map[string]string{"2025-12-25": "Christmas Day"},
+		},
+		{
+			name:         "custom weekend",
+			data:         "weekend: [Friday, saturday]\nholidays: []\n",
+			wantWeekend:  []time.Weekday{time.Friday, time.Saturday},
+			wantHolidays: map[string]string{},
+		},
+		{
+			name:         "no weekend",
+			data:         "weekend: []\n",
+			wantWeekend:  []time.Weekday{},
+			wantHolidays: map[string]string{},
+		},
+		{
+			name:    "invalid weekday",
+			data:    "weekend: [caturday]\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid date",
+			data:    "holidays:\n  - date: 25/12/2025\n    name: Christmas Day\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid yaml",
+			data:    "holidays: [",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseYAML("id", []byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseYAML() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got.Weekend, tt.wantWeekend) {
+				t.Errorf("ParseYAML() weekend = %v, want %v", got.Weekend, tt.wantWeekend)
+			}
+			if !reflect.DeepEqual(got.Holidays, tt.wantHolidays) {
+				t.Errorf("ParseYAML() holidays = %v, want %v", got.Holidays, tt.wantHolidays)
+			}
+		})
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"id.yaml":   "holidays:\n  - date: 2025-08-17\n    name: Independence Day\n",
+		"us.ics":    "BEGIN:VEVENT\nDTSTART;VALUE=DATE:20250704\nSUMMARY:Independence Day\nEND:VEVENT\n",
+		"README.md": "not a calendar",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	registry, err := LoadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, calendar := range registry.List() {
+		names = append(names, calendar.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"id", "us"}) {
+		t.Errorf("LoadDir() calendars = %v, want [id us]", names)
+	}
+
+	us, err := registry.Get("us")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if us.IsBusinessDay(time.Date(2025, 7, 4, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("Independence Day of calendar us is a business day")
+	}
+
+	if _, err := registry.Get("uk"); err == nil {
+		t.Errorf("Registry.Get() found an unknown calendar")
+	}
+
+	t.Run("missing dir", func(t *testing.T) {
+		registry, err := LoadDir(filepath.Join(dir, "missing"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(registry.List()) != 0 {
+			t.Errorf("LoadDir() loaded calendars from a missing dir")
+		}
+	})
+
+	t.Run("invalid calendar", func(t *testing.T) {
+		if err := os.WriteFile(filepath.Join(dir, "bad.yml"), []byte("weekend: [caturday]"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadDir(dir); err == nil {
+			t.Errorf("LoadDir() accepted an invalid calendar")
+		}
+	})
+}
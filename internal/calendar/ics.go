@@ -0,0 +1,114 @@
+package calendar
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/elangreza/scheduler/internal"
+)
+
+const icsDate = "20060102"
+
+var icsUnescaper = strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`)
+
+// ParseICS reads the holidays of an iCalendar file. Every VEVENT is a
+// holiday from its DTSTART date up to, excluding, its DTEND date, or on its
+// DTSTART date alone. Recurring events are not supported, the file must list
+// every holiday.
+func ParseICS(name string, data []byte) (*internal.Calendar, error) {
+	holidays := make(map[string]string)
+
+	var (
+		inEvent          bool
+		start, end, summ string
+		endIsDate        bool
+	)
+	for _, line := range unfoldICS(data) {
+		property, value, _ := strings.Cut(line, ":")
+		params := strings.Split(property, ";")
+		key := strings.ToUpper(params[0])
+
+		switch {
+		case key == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			inEvent = true
+			start, end, summ, endIsDate = "", "", "", false
+		case key == "END" && strings.EqualFold(value, "VEVENT"):
+			if start == "" {
+				return nil, fmt.Errorf("event %q has no DTSTART", summ)
+			}
+			if err := addICSEvent(holidays, start, end, endIsDate, summ); err != nil {
+				return nil, err
+			}
+			inEvent = false
+		case !inEvent:
+		case key == "DTSTART":
+			start = value
+		case key == "DTEND":
+			end = value
+			endIsDate = len(value) == len(icsDate) || hasICSParam(params[1:], "VALUE", "DATE")
+		case key == "SUMMARY":
+			summ = icsUnescaper.Replace(value)
+		case key == "RRULE" || key == "RDATE":
+			return nil, fmt.Errorf("event %q is recurring, recurring events are not supported", summ)
+		}
+	}
+
+	return internal.NewCalendar(name, nil, holidays), nil
+}
+
+func addICSEvent(holidays map[string]string, start, end string, endIsDate bool, summary string) error {
+	if len(start) < len(icsDate) {
+		return fmt.Errorf("invalid DTSTART %q", start)
+	}
+	first, err := time.Parse(icsDate, start[:len(icsDate)])
+	if err != nil {
+		return fmt.Errorf("invalid DTSTART %q", start)
+	}
+
+	last := first
+	if end != "" && endIsDate {
+		exclusive, err := time.Parse(icsDate, end)
+		if err != nil {
+			return fmt.Errorf("invalid DTEND %q", end)
+		}
+		last = exclusive.AddDate(0, 0, -1)
+	}
+
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		holidays[day.Format(time.DateOnly)] = summary
+	}
+	return nil
+}
+
+// unfoldICS splits an iCalendar file into its content lines, joining lines
+// folded with a leading space or tab
+func unfoldICS(data []byte) []string {
+	var lines []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+func hasICSParam(params []string, key, value string) bool {
+	for _, param := range params {
+		k, v, _ := strings.Cut(param, "=")
+		if strings.EqualFold(k, key) && strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
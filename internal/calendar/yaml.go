@@ -0,0 +1,65 @@
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/elangreza/scheduler/internal"
+	"gopkg.in/yaml.v3"
+)
+
+type yamlCalendar struct {
+	// Weekend lists the weekday names that are not business days, Saturday
+	// and Sunday when omitted
+	Weekend  *[]string `yaml:"weekend"`
+	Holidays []struct {
+		Date string `yaml:"date"`
+		Name string `yaml:"name"`
+	} `yaml:"holidays"`
+}
+
+// ParseYAML reads a calendar of the form
+//
+//	weekend: [saturday, sunday]
+//	holidays:
+//	  - date: 2025-12-25
+//	    name: Christmas Day
+func ParseYAML(name string, data []byte) (*internal.Calendar, error) {
+	var file yamlCalendar
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	var weekend []time.Weekday
+	if file.Weekend != nil {
+		weekend = []time.Weekday{}
+		for _, day := range *file.Weekend {
+			weekday, err := parseWeekday(day)
+			if err != nil {
+				return nil, err
+			}
+			weekend = append(weekend, weekday)
+		}
+	}
+
+	holidays := make(map[string]string, len(file.Holidays))
+	for _, holiday := range file.Holidays {
+		date, err := time.Parse(time.DateOnly, holiday.Date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date of holiday %q, must be YYYY-MM-DD: %v", holiday.Name, err)
+		}
+		holidays[date.Format(time.DateOnly)] = holiday.Name
+	}
+
+	return internal.NewCalendar(name, weekend, holidays), nil
+}
+
+func parseWeekday(day string) (time.Weekday, error) {
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if strings.EqualFold(day, weekday.String()) {
+			return weekday, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid weekend day %q", day)
+}
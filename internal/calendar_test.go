@@ -0,0 +1,156 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalendar_IsBusinessDay(t *testing.T) {
+	calendar := NewCalendar("id", nil, map[string]string{"2025-08-17": "Independence Day"})
+	tests := []struct {
+		name string
+		t    time.Time
+		want bool
+	}{
+		{name: "weekday", t: time.Date(2025, 8, 15, 9, 0, 0, 0, time.UTC), want: true},
+		{name: "saturday", t: time.Date(2025, 8, 16, 9, 0, 0, 0, time.UTC), want: false},
+		{name: "holiday", t: time.Date(2025, 8, 17, 9, 0, 0, 0, time.UTC), want: false},
+		{name: "date in the location of t", t: time.Date(2025, 8, 16, 23, 0, 0, 0, time.FixedZone("WIB", 7*60*60)).Add(2 * time.Hour), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := calendar.IsBusinessDay(tt.t); got != tt.want {
+				t.Errorf("Calendar.IsBusinessDay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("custom weekend", func(t *testing.T) {
+		friday := NewCalendar("ae", []time.Weekday{time.Friday, time.Saturday}, nil)
+		if friday.IsBusinessDay(time.Date(2025, 8, 15, 9, 0, 0, 0, time.UTC)) {
+			t.Errorf("Calendar.IsBusinessDay() = true on a weekend friday")
+		}
+		if !friday.IsBusinessDay(time.Date(2025, 8, 17, 9, 0, 0, 0, time.UTC)) {
+			t.Errorf("Calendar.IsBusinessDay() = false on a working sunday")
+		}
+	})
+}
+
+func TestReminder_HolidayPolicy(t *testing.T) {
+	// Wednesday 2025-12-24 and Thursday 2025-12-25 are holidays
+	calendar := NewCalendar("office", nil, map[string]string{
+		"2025-12-24": "Christmas Eve",
+		"2025-12-25": "Christmas Day",
+	})
+	at := func(day, hour int) time.Time {
+		return time.Date(2025, 12, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name        string
+		start       string
+		repeatDaily []int
+		opts        []ReminderOption
+		want        []time.Time
+	}{
+		{
+			name:        "every day on business days only",
+			start:       "2025-12-22T09:00:00Z",
+			repeatDaily: []int{0, 1, 2, 3, 4, 5, 6},
+			opts:        []ReminderOption{WithCalendar("office", HolidaySkip)},
+			want:        []time.Time{at(22, 9), at(23, 9), at(26, 9), at(29, 9), at(30, 9), at(31, 9)},
+		},
+		{
+			name:        "default policy skips",
+			start:       "2025-12-22T09:00:00Z",
+			repeatDaily: []int{0, 1, 2, 3, 4, 5, 6},
+			opts:        []ReminderOption{WithCalendar("office", "")},
+			want:        []time.Time{at(22, 9), at(23, 9), at(26, 9), at(29, 9), at(30, 9), at(31, 9)},
+		},
+		{
+			name:        "wednesday moves to the previous business day",
+			start:       "2025-12-24T09:00:00Z",
+			repeatDaily: []int{3},
+			opts:        []ReminderOption{WithCalendar("office", HolidayPreviousBusinessDay)},
+			want:        []time.Time{at(23, 9), at(31, 9)},
+		},
+		{
+			name:        "wednesday moves to the next business day",
+			start:       "2025-12-24T09:00:00Z",
+			repeatDaily: []int{3},
+			opts:        []ReminderOption{WithCalendar("office", HolidayNextBusinessDay)},
+			want:        []time.Time{at(26, 9), at(31, 9)},
+		},
+		{
+			name:        "every day merges the weekend into monday",
+			start:       "2025-12-26T09:00:00Z",
+			repeatDaily: []int{0, 1, 2, 3, 4, 5, 6},
+			opts:        []ReminderOption{WithCalendar("office", HolidayNextBusinessDay)},
+			want:        []time.Time{at(26, 9), at(29, 9), at(30, 9), at(31, 9)},
+		},
+		{
+			name:        "every day merges the weekend into friday",
+			start:       "2025-12-26T09:00:00Z",
+			repeatDaily: []int{0, 1, 2, 3, 4, 5, 6},
+			opts:        []ReminderOption{WithCalendar("office", HolidayPreviousBusinessDay)},
+			want:        []time.Time{at(26, 9), at(29, 9), at(30, 9), at(31, 9)},
+		},
+		{
+			name:        "weekend days merge into one monday run",
+			start:       "2025-12-27T09:00:00Z",
+			repeatDaily: []int{0, 6},
+			opts:        []ReminderOption{WithCalendar("office", HolidayNextBusinessDay)},
+			want:        []time.Time{at(29, 9)},
+		},
+		{
+			name:  "runs at other wall clocks are not merged",
+			start: "2025-12-27T09:00:00Z",
+			opts:  []ReminderOption{WithCron("0 9,17 * * 6"), WithCalendar("office", HolidayNextBusinessDay)},
+			want:  []time.Time{at(29, 9), at(29, 17)},
+		},
+		{
+			name:  "monthly rrule on a saturday moves to monday",
+			start: "2025-12-27T09:00:00Z",
+			opts:  []ReminderOption{WithRRule("FREQ=MONTHLY;BYMONTHDAY=27"), WithCalendar("office", HolidayNextBusinessDay)},
+			want:  []time.Time{at(29, 9)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reminder, err := NewReminder(1, tt.start, "", "", tt.repeatDaily, withRecipient(tt.opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			reminder.SetCalendar(calendar)
+
+			got := reminder.Occurrences(at(22, 0), at(31, 23), 10)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Reminder.Occurrences() = %v, want %v", got, tt.want)
+			}
+			for i := range tt.want {
+				if !got[i].Equal(tt.want[i]) {
+					t.Errorf("Reminder.Occurrences()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+
+	t.Run("without attached calendar runs on holidays", func(t *testing.T) {
+		reminder, err := NewReminder(1, "2025-12-24T09:00:00Z", "", "", nil, withRecipient(WithCalendar("office", HolidaySkip))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := reminder.FirstRunAt(); !got.Equal(at(24, 9)) {
+			t.Errorf("Reminder.FirstRunAt() = %v, want %v", got, at(24, 9))
+		}
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		if _, err := NewReminder(1, "2025-12-24T09:00:00Z", "", "", nil, WithCalendar("office", "closest_business_day")); err == nil {
+			t.Errorf("NewReminder() accepted an invalid holiday policy")
+		}
+		if _, err := NewReminder(1, "2025-12-24T09:00:00Z", "", "", nil, WithCalendar("", HolidaySkip)); err == nil {
+			t.Errorf("NewReminder() accepted a holiday policy without calendar")
+		}
+	})
+}
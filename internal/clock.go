@@ -0,0 +1,176 @@
+package internal
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+type (
+	// Clock tells the time and waits for it. The scheduler reads the time
+	// through a Clock so tests can control it.
+	Clock interface {
+		Now() time.Time
+		NewTimer(d time.Duration) Timer
+		After(d time.Duration) <-chan time.Time
+	}
+
+	// Timer sends the time on its channel once it expires, see time.Timer
+	Timer interface {
+		C() <-chan time.Time
+		Stop() bool
+		Reset(d time.Duration) bool
+	}
+
+	// SystemClock is the Clock of the time package
+	SystemClock struct{}
+
+	systemTimer struct {
+		timer *time.Timer
+	}
+
+	// FakeClock is a Clock that only moves when it is advanced. Timers of a
+	// FakeClock expire during Advance, in the order of their expiry.
+	FakeClock struct {
+		mu     sync.Mutex
+		now    time.Time
+		timers []*fakeTimer
+	}
+
+	fakeTimer struct {
+		clock    *FakeClock
+		c        chan time.Time
+		deadline time.Time
+	}
+)
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (SystemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{timer: time.NewTimer(d)}
+}
+
+func (SystemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t systemTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+func (t systemTimer) Reset(d time.Duration) bool {
+	return t.timer.Reset(d)
+}
+
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// the channel is buffered like the one of time.Timer, an expiring timer
+	// never blocks Advance
+	t := &fakeTimer{clock: c, c: make(chan time.Time, 1)}
+	c.schedule(t, d)
+	return t
+}
+
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+// Advance moves the clock forward by d and expires the timers due by then
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	end := c.now.Add(d)
+	for len(c.timers) > 0 && !c.timers[0].deadline.After(end) {
+		t := c.timers[0]
+		c.timers = c.timers[1:]
+		// the clock reads the expiry time while the timer fires
+		c.now = t.deadline
+		select {
+		case t.c <- c.now:
+		default:
+		}
+	}
+	c.now = end
+}
+
+// Timers returns the number of timers of the clock that did not expire, tests
+// use it to wait until a goroutine is waiting on the clock
+func (c *FakeClock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.timers)
+}
+
+// schedule adds t to the pending timers, expiring d after now. A timer with
+// a non positive d expires right away.
+func (c *FakeClock) schedule(t *fakeTimer, d time.Duration) {
+	t.deadline = c.now.Add(d)
+	if d <= 0 {
+		select {
+		case t.c <- c.now:
+		default:
+		}
+		return
+	}
+
+	i, _ := slices.BinarySearchFunc(c.timers, t.deadline, func(pending *fakeTimer, deadline time.Time) int {
+		// timers with the same deadline expire in the order they were set
+		if pending.deadline.After(deadline) {
+			return 1
+		}
+		return -1
+	})
+	c.timers = slices.Insert(c.timers, i, t)
+}
+
+// unschedule removes t from the pending timers, it reports whether t was
+// pending
+func (c *FakeClock) unschedule(t *fakeTimer) bool {
+	i := slices.Index(c.timers, t)
+	if i < 0 {
+		return false
+	}
+	c.timers = slices.Delete(c.timers, i, i+1)
+	return true
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	return t.clock.unschedule(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	pending := t.clock.unschedule(t)
+	t.clock.schedule(t, d)
+	return pending
+}
@@ -0,0 +1,89 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2025, 7, 20, 9, 0, 0, 0, time.UTC)
+	expired := func(timer Timer) (time.Time, bool) {
+		select {
+		case at := <-timer.C():
+			return at, true
+		default:
+			return time.Time{}, false
+		}
+	}
+
+	t.Run("advance expires due timers", func(t *testing.T) {
+		clock := NewFakeClock(start)
+		hour := clock.NewTimer(time.Hour)
+		minute := clock.NewTimer(time.Minute)
+
+		clock.Advance(30 * time.Second)
+		if _, ok := expired(minute); ok {
+			t.Errorf("timer expired before its deadline")
+		}
+
+		clock.Advance(30 * time.Second)
+		if at, ok := expired(minute); !ok || !at.Equal(start.Add(time.Minute)) {
+			t.Errorf("minute timer = %v %v, want expired at %v", at, ok, start.Add(time.Minute))
+		}
+		if _, ok := expired(hour); ok {
+			t.Errorf("hour timer expired after a minute")
+		}
+		if clock.Timers() != 1 {
+			t.Errorf("FakeClock.Timers() = %d, want 1", clock.Timers())
+		}
+
+		clock.Advance(2 * time.Hour)
+		if at, ok := expired(hour); !ok || !at.Equal(start.Add(time.Hour)) {
+			t.Errorf("hour timer = %v %v, want expired at %v", at, ok, start.Add(time.Hour))
+		}
+		if got := clock.Now(); !got.Equal(start.Add(2*time.Hour + time.Minute)) {
+			t.Errorf("FakeClock.Now() = %v, want %v", got, start.Add(2*time.Hour+time.Minute))
+		}
+	})
+
+	t.Run("stop and reset", func(t *testing.T) {
+		clock := NewFakeClock(start)
+		timer := clock.NewTimer(time.Minute)
+
+		if !timer.Stop() {
+			t.Errorf("Timer.Stop() = false on a pending timer")
+		}
+		if timer.Stop() {
+			t.Errorf("Timer.Stop() = true on a stopped timer")
+		}
+		clock.Advance(time.Hour)
+		if _, ok := expired(timer); ok {
+			t.Errorf("stopped timer expired")
+		}
+
+		if timer.Reset(time.Minute) {
+			t.Errorf("Timer.Reset() = true on a stopped timer")
+		}
+		clock.Advance(time.Minute)
+		if at, ok := expired(timer); !ok || !at.Equal(start.Add(time.Hour+time.Minute)) {
+			t.Errorf("reset timer = %v %v, want expired at %v", at, ok, start.Add(time.Hour+time.Minute))
+		}
+	})
+
+	t.Run("after", func(t *testing.T) {
+		clock := NewFakeClock(start)
+		after := clock.After(time.Second)
+		clock.Advance(time.Second)
+		select {
+		case <-after:
+		default:
+			t.Errorf("FakeClock.After() did not expire")
+		}
+
+		select {
+		case <-clock.After(0):
+		default:
+			t.Errorf("FakeClock.After(0) did not expire right away")
+		}
+	})
+}
@@ -0,0 +1,252 @@
+package internal
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule is a parsed cron expression. Every field is a bitset of the
+// values it matches.
+type cronSchedule struct {
+	second, minute, hour, dom, month, dow uint64
+
+	// domAny and dowAny are set when the day field is "*" or "?". Standard
+	// cron matches a day when either day field matches, unless one of them
+	// is unrestricted.
+	domAny, dowAny bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	cronSecond = cronField{name: "second", min: 0, max: 59}
+	cronMinute = cronField{name: "minute", min: 0, max: 59}
+	cronHour   = cronField{name: "hour", min: 0, max: 23}
+	cronDom    = cronField{name: "day of month", min: 1, max: 31}
+	cronMonth  = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+		"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+	}}
+	// day of week accepts 7 as Sunday, it is folded into 0 after parsing
+	cronDow = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+	}}
+
+	cronMacros = map[string]string{
+		"@yearly":   "0 0 0 1 1 *",
+		"@annually": "0 0 0 1 1 *",
+		"@monthly":  "0 0 0 1 * *",
+		"@weekly":   "0 0 0 * * 0",
+		"@daily":    "0 0 0 * * *",
+		"@midnight": "0 0 0 * * *",
+		"@hourly":   "0 0 * * * *",
+	}
+)
+
+// parseCron parses a 5 field (minute hour day-of-month month day-of-week) or
+// 6 field (second first) cron expression, or one of the @yearly, @annually,
+// @monthly, @weekly, @daily, @midnight and @hourly macros.
+func parseCron(expr string) (*cronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "@") {
+		macro, ok := cronMacros[strings.ToLower(expr)]
+		if !ok {
+			return nil, fmt.Errorf("unknown cron macro %q", expr)
+		}
+		expr = macro
+	}
+
+	fields := strings.Fields(expr)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("cron expression %q must have 5 or 6 fields, got %d", expr, len(fields))
+	}
+
+	var (
+		c   cronSchedule
+		err error
+	)
+	if c.second, err = cronSecond.parse(fields[0]); err != nil {
+		return nil, err
+	}
+	if c.minute, err = cronMinute.parse(fields[1]); err != nil {
+		return nil, err
+	}
+	if c.hour, err = cronHour.parse(fields[2]); err != nil {
+		return nil, err
+	}
+	if c.dom, err = cronDom.parse(fields[3]); err != nil {
+		return nil, err
+	}
+	if c.month, err = cronMonth.parse(fields[4]); err != nil {
+		return nil, err
+	}
+	if c.dow, err = cronDow.parse(fields[5]); err != nil {
+		return nil, err
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow = c.dow&^(1<<7) | 1
+	}
+	c.domAny = fields[3] == "*" || fields[3] == "?"
+	c.dowAny = fields[5] == "*" || fields[5] == "?"
+
+	return &c, nil
+}
+
+func (f cronField) parse(field string) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		bitsOfPart, err := f.parsePart(part)
+		if err != nil {
+			return 0, err
+		}
+		set |= bitsOfPart
+	}
+	return set, nil
+}
+
+// parsePart parses one element of a list: "*", "?", "n", "a-b", or any of
+// them followed by "/step"
+func (f cronField) parsePart(part string) (uint64, error) {
+	rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+	step := 1
+	if hasStep {
+		var err error
+		step, err = strconv.Atoi(stepPart)
+		if err != nil || step <= 0 {
+			return 0, fmt.Errorf("invalid %s step %q", f.name, stepPart)
+		}
+	}
+
+	var from, to int
+	switch {
+	case rangePart == "*" || rangePart == "?":
+		from, to = f.min, f.max
+		if f.name == cronDow.name {
+			to = 6
+		}
+	case strings.Contains(rangePart, "-"):
+		lo, hi, _ := strings.Cut(rangePart, "-")
+		var err error
+		if from, err = f.value(lo); err != nil {
+			return 0, err
+		}
+		if to, err = f.value(hi); err != nil {
+			return 0, err
+		}
+		if from > to {
+			return 0, fmt.Errorf("invalid %s range %q", f.name, rangePart)
+		}
+	default:
+		var err error
+		if from, err = f.value(rangePart); err != nil {
+			return 0, err
+		}
+		to = from
+		if hasStep {
+			// "a/step" means from a to the end of the range
+			to = f.max
+		}
+	}
+
+	var set uint64
+	for v := from; v <= to; v += step {
+		set |= 1 << v
+	}
+	return set, nil
+}
+
+func (f cronField) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToUpper(s)]; ok {
+		return v, nil
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s value %q", f.name, s)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("%s value %d out of range %d-%d", f.name, v, f.min, f.max)
+	}
+	return v, nil
+}
+
+func (c *cronSchedule) matchDay(t time.Time) bool {
+	domMatch := c.dom&(1<<t.Day()) != 0
+	dowMatch := c.dow&(1<<t.Weekday()) != 0
+	if c.domAny || c.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// next returns the first time strictly after t matching the schedule, in the
+// location of t. The schedule matches wall clock times, see wallClock for wall
+// clocks skipped or repeated by DST transitions. It returns the zero time
+// when nothing matches within five years, e.g., for "0 0 30 2 *".
+func (c *cronSchedule) next(t time.Time) time.Time {
+	wall := floating(t)
+	for {
+		wall = c.nextWall(wall)
+		if wall.IsZero() {
+			return time.Time{}
+		}
+
+		// a repeated wall clock resolves to its earlier occurrence, which
+		// may not be after t
+		if next := wallClock(wall, t.Location()); next.After(t) {
+			return next
+		}
+	}
+}
+
+// nextWall returns the first wall clock strictly after the wall clock t, both
+// read as UTC
+func (c *cronSchedule) nextWall(t time.Time) time.Time {
+	loc := t.Location()
+	limit := t.AddDate(5, 0, 0)
+
+	t = t.Truncate(time.Second).Add(time.Second)
+	for t.Before(limit) {
+		if c.month&(1<<t.Month()) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !c.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if c.hour&(1<<t.Hour()) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if c.minute&(1<<t.Minute()) == 0 {
+			t = t.Truncate(time.Minute).Add(time.Minute)
+			continue
+		}
+		if c.second&(1<<t.Second()) == 0 {
+			// jump to the next matching second of this minute, if any
+			rest := c.second >> (t.Second() + 1)
+			if rest == 0 {
+				t = t.Truncate(time.Minute).Add(time.Minute)
+				continue
+			}
+			t = t.Add(time.Duration(bits.TrailingZeros64(rest)+1) * time.Second)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
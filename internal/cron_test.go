@@ -0,0 +1,135 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		wantErr bool
+	}{
+		{name: "5 fields", expr: "30 8 * * 1-5"},
+		{name: "6 fields", expr: "0 30 8,17 * * MON-FRI"},
+		{name: "steps and lists", expr: "*/15 0-6/2,22 1,15 JAN,jul ?"},
+		{name: "sunday as 7", expr: "0 9 * * 7"},
+		{name: "daily macro", expr: "@daily"},
+		{name: "weekly macro", expr: "@Weekly"},
+		{name: "empty", expr: "", wantErr: true},
+		{name: "unknown macro", expr: "@fortnightly", wantErr: true},
+		{name: "too few fields", expr: "0 9 * *", wantErr: true},
+		{name: "too many fields", expr: "0 0 9 * * * 2025", wantErr: true},
+		{name: "minute out of range", expr: "60 9 * * *", wantErr: true},
+		{name: "day of month out of range", expr: "0 9 0 * *", wantErr: true},
+		{name: "invalid month name", expr: "0 9 1 FOO *", wantErr: true},
+		{name: "reversed range", expr: "0 9 * * 5-1", wantErr: true},
+		{name: "zero step", expr: "*/0 9 * * *", wantErr: true},
+		{name: "not a number", expr: "a 9 * * *", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseCron(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseCron() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCronSchedule_next(t *testing.T) {
+	wib := time.FixedZone("WIB", 7*60*60)
+	type args struct {
+		expr string
+		t    time.Time
+	}
+	tests := []struct {
+		name string
+		args args
+		want time.Time
+	}{
+		{
+			name: "9:00 on the 1st of each month",
+			args: args{expr: "0 9 1 * *", t: time.Date(2025, 7, 20, 10, 38, 23, 0, wib)},
+			want: time.Date(2025, 8, 1, 9, 0, 0, 0, wib),
+		},
+		{
+			name: "strictly after the given time",
+			args: args{expr: "0 9 1 * *", t: time.Date(2025, 8, 1, 9, 0, 0, 0, wib)},
+			want: time.Date(2025, 9, 1, 9, 0, 0, 0, wib),
+		},
+		{
+			name: "weekdays at 08:30 and 17:30, same day",
+			args: args{expr: "30 8,17 * * 1-5", t: time.Date(2025, 7, 21, 9, 0, 0, 0, wib)},
+			want: time.Date(2025, 7, 21, 17, 30, 0, 0, wib),
+		},
+		{
+			name: "weekdays at 08:30 and 17:30, friday evening jumps to monday",
+			args: args{expr: "30 8,17 * * 1-5", t: time.Date(2025, 7, 25, 17, 30, 0, 0, wib)},
+			want: time.Date(2025, 7, 28, 8, 30, 0, 0, wib),
+		},
+		{
+			name: "every 15 minutes",
+			args: args{expr: "*/15 * * * *", t: time.Date(2025, 7, 20, 10, 38, 23, 0, time.UTC)},
+			want: time.Date(2025, 7, 20, 10, 45, 0, 0, time.UTC),
+		},
+		{
+			name: "6 fields with seconds",
+			args: args{expr: "10,40 * * * * *", t: time.Date(2025, 7, 20, 10, 38, 23, 0, time.UTC)},
+			want: time.Date(2025, 7, 20, 10, 38, 40, 0, time.UTC),
+		},
+		{
+			name: "6 fields with seconds, next minute",
+			args: args{expr: "10,40 * * * * *", t: time.Date(2025, 7, 20, 10, 38, 45, 0, time.UTC)},
+			want: time.Date(2025, 7, 20, 10, 39, 10, 0, time.UTC),
+		},
+		{
+			name: "daily macro",
+			args: args{expr: "@daily", t: time.Date(2025, 7, 20, 10, 38, 23, 0, wib)},
+			want: time.Date(2025, 7, 21, 0, 0, 0, 0, wib),
+		},
+		{
+			name: "weekly macro runs on sunday",
+			args: args{expr: "@weekly", t: time.Date(2025, 7, 20, 10, 38, 23, 0, wib)},
+			want: time.Date(2025, 7, 27, 0, 0, 0, 0, wib),
+		},
+		{
+			name: "yearly macro",
+			args: args{expr: "@yearly", t: time.Date(2025, 7, 20, 10, 38, 23, 0, wib)},
+			want: time.Date(2026, 1, 1, 0, 0, 0, 0, wib),
+		},
+		{
+			name: "sunday as 7",
+			args: args{expr: "0 9 * * 7", t: time.Date(2025, 7, 21, 0, 0, 0, 0, wib)},
+			want: time.Date(2025, 7, 27, 9, 0, 0, 0, wib),
+		},
+		{
+			name: "day of month or day of week when both are restricted",
+			args: args{expr: "0 9 15 * MON", t: time.Date(2025, 7, 8, 0, 0, 0, 0, wib)},
+			want: time.Date(2025, 7, 14, 9, 0, 0, 0, wib),
+		},
+		{
+			name: "29th of february",
+			args: args{expr: "0 0 29 2 *", t: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+			want: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "impossible date",
+			args: args{expr: "0 0 30 2 *", t: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+			want: time.Time{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := parseCron(tt.args.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := c.next(tt.args.t); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cronSchedule.next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package emailtemplate
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/elangreza/scheduler/internal"
+)
+
+const (
+	// subjectFile, textFile and htmlFile are the files of a template set
+	subjectFile = "subject.txt"
+	textFile    = "body.txt"
+	htmlFile    = "body.html"
+
+	// defaultSubject and defaultText are the built-in templates, they render
+	// the task name as subject and its description followed by the action
+	// links as body
+	defaultSubject = `{{.Task.Name}}`
+	defaultText    = `{{if .Links}}{{if .Escalated}}Escalated, schedule {{.Schedule.ParentID}} was not acknowledged.
+
+{{end}}{{.Task.Description}}
+
+Acknowledge: {{.Links.Acknowledge}}
+{{range .Links.Snooze}}Snooze for {{.Label}}: {{.URL}}
+{{end}}{{else}}{{.Task.Description}}{{end}}`
+)
+
+type (
+	// Data is what the templates of an email render
+	Data struct {
+		Task     internal.Task
+		Reminder internal.Reminder
+		Schedule internal.Schedule
+		// OccurrenceAt is the occurrence the schedule notifies, in the time
+		// zone of the recipients of the email
+		OccurrenceAt time.Time
+		// Escalated is set when the schedule escalates Schedule.ParentID
+		Escalated bool
+		// Links is nil when emails have no action links
+		Links *Links
+	}
+
+	// Links are the signed one-click links of the schedule of an email
+	Links struct {
+		Acknowledge string
+		// Snooze is empty for escalations, they can only be acknowledged
+		Snooze []SnoozeLink
+	}
+
+	SnoozeLink struct {
+		Label string // e.g., "1 hour"
+		URL   string
+	}
+
+	// Email is a rendered email, HTML is empty when no html template applies
+	Email struct {
+		Subject string
+		Text    string
+		HTML    string
+	}
+
+	// set holds the templates of emails, a nil template falls back to the
+	// one of the default set
+	set struct {
+		subject *texttemplate.Template
+		text    *texttemplate.Template
+		html    *htmltemplate.Template
+	}
+
+	// Registry holds the default email templates and the named template sets
+	// tasks can reference to override them
+	Registry struct {
+		defaults set
+		sets     map[string]*set
+	}
+)
+
+// NewRegistry returns a registry with the built-in default templates only,
+// emails have no html body
+func NewRegistry() *Registry {
+	return &Registry{
+		defaults: set{
+			subject: texttemplate.Must(texttemplate.New(subjectFile).Parse(defaultSubject)),
+			text:    texttemplate.Must(texttemplate.New(textFile).Parse(defaultText)),
+		},
+		sets: make(map[string]*set),
+	}
+}
+
+// LoadDir loads the templates of dir. The subject.txt, body.txt and body.html
+// files of dir replace the built-in default templates, and every
+// subdirectory is a template set named after it, e.g., "dir/billing" is the
+// set "billing". A set only overrides the default templates it has files
+// for. A missing dir loads no templates.
+func LoadDir(dir string) (*Registry, error) {
+	registry := NewRegistry()
+
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return registry, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	defaults, err := parseSet(dir)
+	if err != nil {
+		return nil, err
+	}
+	if defaults.subject != nil {
+		registry.defaults.subject = defaults.subject
+	}
+	if defaults.text != nil {
+		registry.defaults.text = defaults.text
+	}
+	registry.defaults.html = defaults.html
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		set, err := parseSet(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		registry.sets[entry.Name()] = set
+	}
+
+	return registry, nil
+}
+
+// parseSet parses the template files of dir, a missing file leaves its
+// template nil
+func parseSet(dir string) (*set, error) {
+	var s set
+
+	subject, err := readTemplate(dir, subjectFile)
+	if err != nil {
+		return nil, err
+	}
+	if subject != "" {
+		if s.subject, err = texttemplate.New(subjectFile).Parse(subject); err != nil {
+			return nil, fmt.Errorf("email template %s: %v", filepath.Join(dir, subjectFile), err)
+		}
+	}
+
+	text, err := readTemplate(dir, textFile)
+	if err != nil {
+		return nil, err
+	}
+	if text != "" {
+		if s.text, err = texttemplate.New(textFile).Parse(text); err != nil {
+			return nil, fmt.Errorf("email template %s: %v", filepath.Join(dir, textFile), err)
+		}
+	}
+
+	html, err := readTemplate(dir, htmlFile)
+	if err != nil {
+		return nil, err
+	}
+	if html != "" {
+		if s.html, err = htmltemplate.New(htmlFile).Parse(html); err != nil {
+			return nil, fmt.Errorf("email template %s: %v", filepath.Join(dir, htmlFile), err)
+		}
+	}
+
+	return &s, nil
+}
+
+// readTemplate returns the content of the file name of dir, empty when it
+// does not exist
+func readTemplate(dir, name string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Has reports whether name is a template set, the empty name is the default
+// templates
+func (r *Registry) Has(name string) bool {
+	if name == "" {
+		return true
+	}
+	_, ok := r.sets[name]
+	return ok
+}
+
+// Render renders the email of data with the template set name, the default
+// templates when name is empty. The subject is rendered on a single line.
+func (r *Registry) Render(name string, data Data) (*Email, error) {
+	templates := r.defaults
+	if name != "" {
+		s, ok := r.sets[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown email template %q", name)
+		}
+		if s.subject != nil {
+			templates.subject = s.subject
+		}
+		if s.text != nil {
+			templates.text = s.text
+		}
+		if s.html != nil {
+			templates.html = s.html
+		}
+	}
+
+	var email Email
+	var b bytes.Buffer
+	if err := templates.subject.Execute(&b, data); err != nil {
+		return nil, fmt.Errorf("render email subject: %v", err)
+	}
+	// a subject is a single header line
+	email.Subject = strings.Join(strings.Fields(b.String()), " ")
+
+	b.Reset()
+	if err := templates.text.Execute(&b, data); err != nil {
+		return nil, fmt.Errorf("render email text: %v", err)
+	}
+	email.Text = b.String()
+
+	if templates.html != nil {
+		b.Reset()
+		if err := templates.html.Execute(&b, data); err != nil {
+			return nil, fmt.Errorf("render email html: %v", err)
+		}
+		email.HTML = b.String()
+	}
+
+	return &email, nil
+}
@@ -0,0 +1,173 @@
+package emailtemplate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/elangreza/scheduler/internal"
+)
+
+func TestRegistry_Render(t *testing.T) {
+	task := internal.Task{Name: "water the plants", Description: "every pot"}
+	links := &Links{
+		Acknowledge: "ack/7",
+		Snooze:      []SnoozeLink{{Label: "1 hour", URL: "snooze/7/1h"}},
+	}
+
+	tests := []struct {
+		name string
+		data Data
+		want string
+	}{
+		{
+			name: "without links",
+			data: Data{Task: task},
+			want: "every pot",
+		},
+		{
+			name: "with links",
+			data: Data{Task: task, Links: links},
+			want: "every pot\n\nAcknowledge: ack/7\nSnooze for 1 hour: snooze/7/1h\n",
+		},
+		{
+			name: "escalation",
+			data: Data{Task: task, Schedule: internal.Schedule{ParentID: 3}, Escalated: true, Links: &Links{Acknowledge: "ack/7"}},
+			want: "Escalated, schedule 3 was not acknowledged.\n\nevery pot\n\nAcknowledge: ack/7\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			email, err := NewRegistry().Render("", tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if email.Subject != task.Name || email.HTML != "" {
+				t.Errorf("Registry.Render() subject = %q, html = %q", email.Subject, email.HTML)
+			}
+			if email.Text != tt.want {
+				t.Errorf("Registry.Render() text = %q, want %q", email.Text, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"subject.txt":         "Reminder:\n  {{.Task.Name}}\n",
+		"body.html":           "<h1>{{.Task.Name}}</h1>",
+		"billing/body.txt":    "Invoice {{.Task.Description}}",
+		"billing/README.md":   "not a template",
+		"empty/.gitkeep":      "",
+		"README.md":           "not a template",
+		"partials/body.html":  "<b>{{.Task.Description}}</b>",
+		"partials/ignored.go": "package ignored",
+	}
+	for name, data := range files {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	registry, err := LoadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := Data{Task: internal.Task{Name: "pay <rent>", Description: "#42"}}
+	tests := []struct {
+		name     string
+		template string
+		want     Email
+	}{
+		{
+			name:     "default",
+			template: "",
+			want:     Email{Subject: "Reminder: pay <rent>", Text: "#42", HTML: "<h1>pay &lt;rent&gt;</h1>"},
+		},
+		{
+			name:     "text override",
+			template: "billing",
+			want:     Email{Subject: "Reminder: pay <rent>", Text: "Invoice #42", HTML: "<h1>pay &lt;rent&gt;</h1>"},
+		},
+		{
+			name:     "empty set",
+			template: "empty",
+			want:     Email{Subject: "Reminder: pay <rent>", Text: "#42", HTML: "<h1>pay &lt;rent&gt;</h1>"},
+		},
+		{
+			name:     "html override",
+			template: "partials",
+			want:     Email{Subject: "Reminder: pay <rent>", Text: "#42", HTML: "<b>#42</b>"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !registry.Has(tt.template) {
+				t.Fatalf("Registry.Has(%q) = false", tt.template)
+			}
+			email, err := registry.Render(tt.template, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *email != tt.want {
+				t.Errorf("Registry.Render() = %+v, want %+v", *email, tt.want)
+			}
+		})
+	}
+
+	if registry.Has("marketing") {
+		t.Errorf("Registry.Has() found an unknown template set")
+	}
+	if _, err := registry.Render("marketing", data); err == nil {
+		t.Errorf("Registry.Render() rendered an unknown template set")
+	}
+
+	t.Run("missing dir", func(t *testing.T) {
+		registry, err := LoadDir(filepath.Join(dir, "missing"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		email, err := registry.Render("", data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *email != (Email{Subject: "pay <rent>", Text: "#42"}) {
+			t.Errorf("LoadDir() did not fall back to the built-in templates, rendered %+v", *email)
+		}
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		if err := os.WriteFile(filepath.Join(dir, "billing", "subject.txt"), []byte("{{.Task.Name"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadDir(dir); err == nil {
+			t.Errorf("LoadDir() accepted an invalid template")
+		}
+	})
+}
+
+func TestLoadDir_shipped(t *testing.T) {
+	registry, err := LoadDir(filepath.Join("..", "..", "templates", "emails"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := Data{
+		Task:      internal.Task{Name: "water the plants", Description: "every pot"},
+		Schedule:  internal.Schedule{ParentID: 3},
+		Escalated: true,
+		Links:     &Links{Acknowledge: "ack/7"},
+	}
+	email, err := registry.Render("", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if email.Subject != "[Escalated] water the plants" || email.Text == "" || email.HTML == "" {
+		t.Errorf("Registry.Render() = %+v", *email)
+	}
+}
@@ -0,0 +1,38 @@
+package internal
+
+import "errors"
+
+// ErrNotFound is returned by repositories when the requested row does not exist.
+var ErrNotFound = errors.New("not found")
+
+// ErrConflict is returned by repositories when a row changed and no longer
+// allows the requested update.
+var ErrConflict = errors.New("conflicts with its current state")
+
+// ValidationError marks an error caused by invalid user input, so the
+// transport layer can report it as a client error.
+type ValidationError struct {
+	Err error
+}
+
+func (e ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// PermanentError marks a delivery failure that fails again on every attempt,
+// e.g., a webhook answering 4xx, so it is not retried.
+type PermanentError struct {
+	Err error
+}
+
+func (e PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e PermanentError) Unwrap() error {
+	return e.Err
+}
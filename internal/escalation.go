@@ -0,0 +1,150 @@
+package internal
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"time"
+)
+
+const (
+	// ScheduleEscalation is a schedule notifying a step of the escalation
+	// policy of its reminder, its ParentID is the escalated schedule
+	ScheduleEscalation = "escalation"
+
+	// maxEscalationSteps bounds the steps of an escalation policy
+	maxEscalationSteps = 10
+)
+
+type (
+	// EscalationPolicy notifies more recipients, one step after another, while
+	// a fired schedule of a Reminder stays unacknowledged. Policies are shared
+	// by reminders through their EscalationPolicyID.
+	EscalationPolicy struct {
+		ID        int64            `json:"id"`
+		Name      string           `json:"name"`
+		Steps     []EscalationStep `json:"steps"`
+		CreatedAt time.Time        `json:"created_at"`
+		UpdatedAt time.Time        `json:"updated_at"`
+	}
+
+	// EscalationStep fires once the previous notification of the schedule,
+	// the schedule itself for the first step, stayed unacknowledged for After
+	EscalationStep struct {
+		After      string   `json:"after"`       // e.g., "15m", "1h"
+		Recipients []string `json:"recipients"`  // email addresses notified by the step
+		Channel    string   `json:"channel"`     // delivery channel of the step, defaults to email
+		WebhookURL string   `json:"webhook_url"` // required when Channel is "webhook"
+
+		// delay is parsed After
+		delay time.Duration
+	}
+)
+
+func NewEscalationPolicy(name string, steps []EscalationStep) (*EscalationPolicy, error) {
+	policy := &EscalationPolicy{
+		Name:  name,
+		Steps: steps,
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// Validate checks the policy and parses the delays of its steps. Policies
+// loaded from storage must be validated before they escalate a schedule.
+func (p *EscalationPolicy) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("escalation policy name cannot be empty")
+	}
+
+	if len(p.Steps) == 0 || len(p.Steps) > maxEscalationSteps {
+		return fmt.Errorf("escalation policy must have between 1 and %d steps", maxEscalationSteps)
+	}
+
+	for i := range p.Steps {
+		if err := p.Steps[i].isValid(); err != nil {
+			return fmt.Errorf("invalid escalation step %d: %v", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *EscalationStep) isValid() error {
+	var err error
+	s.delay, err = time.ParseDuration(s.After)
+	if err != nil {
+		return fmt.Errorf("invalid after duration: %v", err)
+	}
+	if s.delay <= 0 {
+		return fmt.Errorf("after duration must be positive")
+	}
+
+	for _, recipient := range s.Recipients {
+		if _, err := mail.ParseAddress(recipient); err != nil {
+			return fmt.Errorf("invalid recipient %q: %v", recipient, err)
+		}
+	}
+
+	switch s.Channel {
+	case "", ChannelEmail:
+		if len(s.Recipients) == 0 {
+			return fmt.Errorf("email step requires recipients")
+		}
+	case ChannelLog:
+	case ChannelWebhook:
+		u, err := url.Parse(s.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid webhook url %q, must be an absolute http or https url", s.WebhookURL)
+		}
+	default:
+		return fmt.Errorf("invalid channel %q, must be one of %s, %s or %s", s.Channel, ChannelEmail, ChannelWebhook, ChannelLog)
+	}
+
+	return nil
+}
+
+// Escalated returns a copy of the Reminder notifying the step-th step, from
+// 1, of its escalation policy instead of its own recipients
+func (s *Reminder) Escalated(step int) (*Reminder, error) {
+	if s.Escalation == nil || step < 1 || step > len(s.Escalation.Steps) {
+		return nil, fmt.Errorf("reminder %d has no escalation step %d", s.ID, step)
+	}
+
+	escalated := *s
+	escalated.Recipients = s.Escalation.Steps[step-1].Recipients
+	escalated.Channel = s.Escalation.Steps[step-1].Channel
+	escalated.WebhookURL = s.Escalation.Steps[step-1].WebhookURL
+	return &escalated, nil
+}
+
+// Escalate returns the escalation schedule following the fired schedule, it
+// notifies the next step of the escalation policy of reminder unless the
+// chain is acknowledged first. It returns nil once the policy has no more
+// steps. Every escalation schedule of a chain has the escalated schedule as
+// parent. Deferred schedules do not escalate, the schedule they deferred
+// did.
+func (s *Schedule) Escalate(reminder *Reminder, now time.Time) *Schedule {
+	if reminder.Escalation == nil || s.Kind == ScheduleDeferred {
+		return nil
+	}
+
+	parentID, step := s.ID, 1
+	if s.Kind == ScheduleEscalation {
+		parentID, step = s.ParentID, s.Step+1
+	}
+	if step > len(reminder.Escalation.Steps) {
+		return nil
+	}
+
+	escalation := NewSchedule(s.TaskID, s.ReminderID, now.Add(reminder.Escalation.Steps[step-1].delay))
+	escalation.Kind = ScheduleEscalation
+	escalation.ParentID = parentID
+	escalation.Step = step
+	escalation.OccurrenceAt = s.OccurrenceAt
+	return escalation
+}
@@ -0,0 +1,143 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewEscalationPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		steps   []EscalationStep
+		wantErr bool
+	}{
+		{
+			name:   "email steps",
+			policy: "on call",
+			steps: []EscalationStep{
+				{After: "15m", Recipients: []string{"lead@example.com"}},
+				{After: "1h", Recipients: []string{"manager@example.com"}, Channel: ChannelEmail},
+			},
+		},
+		{
+			name:   "webhook and log steps",
+			policy: "on call",
+			steps: []EscalationStep{
+				{After: "15m", Channel: ChannelWebhook, WebhookURL: "https://example.com/page"},
+				{After: "30m", Channel: ChannelLog},
+			},
+		},
+		{
+			name:    "empty name",
+			steps:   []EscalationStep{{After: "15m", Recipients: []string{"lead@example.com"}}},
+			wantErr: true,
+		},
+		{
+			name:    "no steps",
+			policy:  "on call",
+			wantErr: true,
+		},
+		{
+			name:    "invalid after",
+			policy:  "on call",
+			steps:   []EscalationStep{{After: "soon", Recipients: []string{"lead@example.com"}}},
+			wantErr: true,
+		},
+		{
+			name:    "non positive after",
+			policy:  "on call",
+			steps:   []EscalationStep{{After: "0s", Recipients: []string{"lead@example.com"}}},
+			wantErr: true,
+		},
+		{
+			name:    "email step without recipients",
+			policy:  "on call",
+			steps:   []EscalationStep{{After: "15m"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid recipient",
+			policy:  "on call",
+			steps:   []EscalationStep{{After: "15m", Recipients: []string{"lead"}}},
+			wantErr: true,
+		},
+		{
+			name:    "webhook step without url",
+			policy:  "on call",
+			steps:   []EscalationStep{{After: "15m", Channel: ChannelWebhook}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid channel",
+			policy:  "on call",
+			steps:   []EscalationStep{{After: "15m", Channel: "sms"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewEscalationPolicy(tt.policy, tt.steps)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewEscalationPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSchedule_Escalate(t *testing.T) {
+	now := time.Date(2025, 7, 20, 9, 0, 0, 0, time.UTC)
+	occurrence := now.Add(-time.Hour)
+
+	policy, err := NewEscalationPolicy("on call", []EscalationStep{
+		{After: "15m", Recipients: []string{"lead@example.com"}},
+		{After: "1h", Channel: ChannelLog},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reminder := &Reminder{ID: 3, Recipients: []string{"ops@example.com"}, Escalation: policy}
+
+	fired := &Schedule{ID: 5, TaskID: 2, ReminderID: 3, Status: StatusSuccess, Kind: ScheduleOccurrence, OccurrenceAt: occurrence}
+
+	first := fired.Escalate(reminder, now)
+	if first == nil {
+		t.Fatalf("Schedule.Escalate() = nil, want the first step")
+	}
+	if first.Kind != ScheduleEscalation || first.ParentID != 5 || first.Step != 1 || !first.NotifyAt.Equal(now.Add(15*time.Minute)) || !first.OccurrenceAt.Equal(occurrence) {
+		t.Errorf("Schedule.Escalate() = %+v", first)
+	}
+
+	first.ID = 6
+	second := first.Escalate(reminder, now)
+	if second == nil {
+		t.Fatalf("Schedule.Escalate() of the first step = nil, want the second step")
+	}
+	if second.ParentID != 5 || second.Step != 2 || !second.NotifyAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("Schedule.Escalate() of the first step = %+v", second)
+	}
+
+	if last := second.Escalate(reminder, now); last != nil {
+		t.Errorf("Schedule.Escalate() of the last step = %+v, want nil", last)
+	}
+
+	if none := fired.Escalate(&Reminder{ID: 3}, now); none != nil {
+		t.Errorf("Schedule.Escalate() without a policy = %+v, want nil", none)
+	}
+
+	escalated, err := reminder.Escalated(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if escalated.DeliveryChannel() != ChannelLog || len(escalated.Recipients) != 0 || reminder.DeliveryChannel() != ChannelEmail {
+		t.Errorf("Reminder.Escalated(2) = %+v", escalated)
+	}
+	if _, err := reminder.Escalated(3); err == nil {
+		t.Errorf("Reminder.Escalated(3) of a two step policy succeeded")
+	}
+
+	second.Status = StatusSuccess
+	if _, err := second.Snooze(now.Add(time.Hour), now); err == nil {
+		t.Errorf("Schedule.Snooze() snoozed an escalation")
+	}
+}
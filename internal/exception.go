@@ -0,0 +1,100 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// ExceptionSkip drops every run of a Reminder on a date
+	ExceptionSkip = "skip"
+	// ExceptionOverride moves a single run of a Reminder to another time
+	ExceptionOverride = "override"
+)
+
+// ReminderException changes the runs of a Reminder without changing its
+// recurrence. Skips take precedence over overrides of the same run.
+type ReminderException struct {
+	ID           int64     `json:"id"`
+	ReminderID   int64     `json:"reminder_id"`
+	Kind         string    `json:"kind"`          // ExceptionSkip or ExceptionOverride
+	Date         string    `json:"date"`          // skipped date, e.g., "2025-12-25", in the location of the Reminder
+	OccurrenceAt time.Time `json:"occurrence_at"` // overridden run, as computed by the recurrence
+	RunAt        time.Time `json:"run_at"`        // time the overridden run fires at instead
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func NewReminderException(reminderID int64, kind, date, occurrenceAt, runAt string) (*ReminderException, error) {
+	exception := &ReminderException{
+		ReminderID: reminderID,
+		Kind:       kind,
+	}
+
+	switch kind {
+	case ExceptionSkip:
+		if occurrenceAt != "" || runAt != "" {
+			return nil, fmt.Errorf("skip exception only accepts a date")
+		}
+
+		if _, err := time.Parse(time.DateOnly, date); err != nil {
+			return nil, fmt.Errorf("invalid date format, must be YYYY-MM-DD: %v", err)
+		}
+		exception.Date = date
+	case ExceptionOverride:
+		if date != "" {
+			return nil, fmt.Errorf("override exception does not accept a date")
+		}
+
+		var err error
+		exception.OccurrenceAt, err = time.Parse(time.RFC3339, occurrenceAt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid occurrence time format: %v", err)
+		}
+
+		exception.RunAt, err = time.Parse(time.RFC3339, runAt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid run time format: %v", err)
+		}
+	default:
+		return nil, fmt.Errorf("invalid exception kind %q, must be %s or %s", kind, ExceptionSkip, ExceptionOverride)
+	}
+
+	return exception, nil
+}
+
+// skipped reports whether the run at t falls on a skipped date, on a holiday
+// skipped by the holiday policy, or is moved by the holiday policy onto
+// another run
+func (s *Reminder) skipped(t time.Time) bool {
+	return s.holidaySkipped(t) || s.exceptionSkipped(t) || s.holidayMerged(t)
+}
+
+// exceptionSkipped reports whether the run at t falls on a date skipped by a
+// skip exception
+func (s *Reminder) exceptionSkipped(t time.Time) bool {
+	date := t.In(s.Location()).Format(time.DateOnly)
+	for _, exception := range s.Exceptions {
+		if exception.Kind == ExceptionSkip && exception.Date == date {
+			return true
+		}
+	}
+	return false
+}
+
+// RunAt returns the time the occurrence of the Reminder fires at, the
+// occurrence itself unless an override exception or the holiday policy moved
+// it. Overrides take precedence over the holiday policy.
+func (s *Reminder) RunAt(occurrence time.Time) time.Time {
+	for _, exception := range s.Exceptions {
+		if exception.Kind == ExceptionOverride && exception.OccurrenceAt.Equal(occurrence) {
+			return exception.RunAt.In(s.Location())
+		}
+	}
+	return s.holidayShift(occurrence)
+}
+
+// IsOccurrence reports whether the recurrence of the Reminder runs at t, once
+// skipped dates are removed
+func (s *Reminder) IsOccurrence(t time.Time) bool {
+	return s.seek(s.firstRun(), t).Equal(t)
+}
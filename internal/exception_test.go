@@ -0,0 +1,177 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewReminderException(t *testing.T) {
+	type args struct {
+		kind         string
+		date         string
+		occurrenceAt string
+		runAt        string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *ReminderException
+		wantErr bool
+	}{
+		{
+			name: "skip",
+			args: args{kind: ExceptionSkip, date: "2025-12-25"},
+			want: &ReminderException{ReminderID: 1, Kind: ExceptionSkip, Date: "2025-12-25"},
+		},
+		{
+			name: "override",
+			args: args{kind: ExceptionOverride, occurrenceAt: "2025-07-21T09:00:00Z", runAt: "2025-07-21T11:00:00Z"},
+			want: &ReminderException{
+				ReminderID:   1,
+				Kind:         ExceptionOverride,
+				OccurrenceAt: time.Date(2025, 7, 21, 9, 0, 0, 0, time.UTC),
+				RunAt:        time.Date(2025, 7, 21, 11, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:    "invalid kind",
+			args:    args{kind: "move", date: "2025-12-25"},
+			wantErr: true,
+		},
+		{
+			name:    "skip with invalid date",
+			args:    args{kind: ExceptionSkip, date: "25-12-2025"},
+			wantErr: true,
+		},
+		{
+			name:    "skip with occurrence time",
+			args:    args{kind: ExceptionSkip, date: "2025-12-25", occurrenceAt: "2025-12-25T09:00:00Z"},
+			wantErr: true,
+		},
+		{
+			name:    "override without run time",
+			args:    args{kind: ExceptionOverride, occurrenceAt: "2025-07-21T09:00:00Z"},
+			wantErr: true,
+		},
+		{
+			name:    "override with date",
+			args:    args{kind: ExceptionOverride, date: "2025-07-21", occurrenceAt: "2025-07-21T09:00:00Z", runAt: "2025-07-21T11:00:00Z"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewReminderException(1, tt.args.kind, tt.args.date, tt.args.occurrenceAt, tt.args.runAt)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewReminderException() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewReminderException() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReminder_Exceptions(t *testing.T) {
+	// a weekday reminder at 09:00, skipping a public holiday on Wednesday
+	// 2025-07-23 and moving the run of Thursday 2025-07-24 to 14:00
+	reminder, err := NewReminder(1, "2025-07-21T09:00:00+07:00", "", "", []int{1, 2, 3, 4, 5}, withRecipient(WithTimezone("Asia/Jakarta"))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jakarta := reminder.Location()
+	at := func(day, hour int) time.Time {
+		return time.Date(2025, 7, day, hour, 0, 0, 0, jakarta)
+	}
+	reminder.Exceptions = []ReminderException{
+		{Kind: ExceptionSkip, Date: "2025-07-23"},
+		{Kind: ExceptionOverride, OccurrenceAt: at(24, 9), RunAt: at(24, 14)},
+	}
+
+	t.Run("GetNextRunAt passes over skipped dates", func(t *testing.T) {
+		if got := reminder.GetNextRunAt(at(22, 9)); !got.Equal(at(24, 9)) {
+			t.Errorf("Reminder.GetNextRunAt() = %v, want %v", got, at(24, 9))
+		}
+	})
+
+	t.Run("GetNextRunAt follows the occurrence of an override", func(t *testing.T) {
+		if got := reminder.GetNextRunAt(at(24, 9)); !got.Equal(at(25, 9)) {
+			t.Errorf("Reminder.GetNextRunAt() = %v, want %v", got, at(25, 9))
+		}
+	})
+
+	t.Run("FirstRunAt passes over a skipped start date", func(t *testing.T) {
+		skipped := *reminder
+		skipped.Exceptions = []ReminderException{{Kind: ExceptionSkip, Date: "2025-07-21"}}
+		if got := skipped.FirstRunAt(); !got.Equal(at(22, 9)) {
+			t.Errorf("Reminder.FirstRunAt() = %v, want %v", got, at(22, 9))
+		}
+	})
+
+	t.Run("RunAt moves overridden occurrences only", func(t *testing.T) {
+		if got := reminder.RunAt(at(24, 9)); !got.Equal(at(24, 14)) {
+			t.Errorf("Reminder.RunAt() = %v, want %v", got, at(24, 14))
+		}
+		if got := reminder.RunAt(at(25, 9)); !got.Equal(at(25, 9)) {
+			t.Errorf("Reminder.RunAt() = %v, want %v", got, at(25, 9))
+		}
+	})
+
+	t.Run("IsOccurrence", func(t *testing.T) {
+		for _, tt := range []struct {
+			t    time.Time
+			want bool
+		}{
+			{t: at(22, 9), want: true},
+			{t: at(22, 10), want: false},
+			{t: at(23, 9), want: false},
+			{t: at(26, 9), want: false},
+		} {
+			if got := reminder.IsOccurrence(tt.t); got != tt.want {
+				t.Errorf("Reminder.IsOccurrence(%v) = %v, want %v", tt.t, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("IsOccurrence long after the start", func(t *testing.T) {
+		frequent, err := NewReminder(1, "2025-01-01T00:00:00Z", "", "5m", nil, withRecipient()...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		frequent.Exceptions = []ReminderException{{Kind: ExceptionSkip, Date: "2026-10-02"}}
+
+		for _, tt := range []struct {
+			t    time.Time
+			want bool
+		}{
+			{t: time.Date(2026, 10, 1, 12, 5, 0, 0, time.UTC), want: true},
+			{t: time.Date(2026, 10, 1, 12, 6, 0, 0, time.UTC), want: false},
+			{t: time.Date(2026, 10, 2, 12, 5, 0, 0, time.UTC), want: false},
+			{t: time.Date(2024, 12, 31, 23, 55, 0, 0, time.UTC), want: false},
+		} {
+			if got := frequent.IsOccurrence(tt.t); got != tt.want {
+				t.Errorf("Reminder.IsOccurrence(%v) = %v, want %v", tt.t, got, tt.want)
+			}
+		}
+
+		daily := *reminder
+		if !daily.IsOccurrence(time.Date(2035, 7, 23, 9, 0, 0, 0, jakarta)) {
+			t.Errorf("Reminder.IsOccurrence() = false for a daily run ten years after the start")
+		}
+	})
+
+	t.Run("Occurrences applies skips and overrides", func(t *testing.T) {
+		want := []time.Time{at(21, 9), at(22, 9), at(24, 14), at(25, 9)}
+		got := reminder.Occurrences(at(21, 0), at(25, 23), 10)
+		if len(got) != len(want) {
+			t.Fatalf("Reminder.Occurrences() = %v, want %v", got, want)
+		}
+		for i := range want {
+			if !got[i].Equal(want[i]) {
+				t.Errorf("Reminder.Occurrences()[%d] = %v, want %v", i, got[i], want[i])
+			}
+		}
+	})
+}
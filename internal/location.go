@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"time"
+	// embed the IANA time zone database, reminders must not depend on the
+	// zoneinfo files of the host
+	_ "time/tzdata"
+)
+
+// wallClock returns the time at the wall clock of wall, read as if it were UTC,
+// in loc. A wall clock skipped by a DST transition is shifted forward by the
+// length of the gap, e.g., 02:30 becomes 03:30 when clocks jump from 02:00 to
+// 03:00. A wall clock repeated by a DST transition resolves to its earlier
+// occurrence.
+func wallClock(wall time.Time, loc *time.Location) time.Time {
+	t := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), loc)
+
+	// the offsets in effect around t, a transition near the wall clock is
+	// one of the bounds of the zone of t
+	_, offset := t.Zone()
+	offsets := []int{offset}
+	start, end := t.ZoneBounds()
+	if !start.IsZero() {
+		_, before := start.Add(-time.Nanosecond).Zone()
+		offsets = append(offsets, before)
+	}
+	if !end.IsZero() {
+		_, after := end.Zone()
+		offsets = append(offsets, after)
+	}
+
+	naive := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), time.UTC)
+
+	var earliest time.Time
+	smallest := offset
+	for _, o := range offsets {
+		smallest = min(smallest, o)
+
+		candidate := naive.Add(-time.Duration(o) * time.Second).In(loc)
+		if _, actual := candidate.Zone(); actual != o {
+			continue
+		}
+		if earliest.IsZero() || candidate.Before(earliest) {
+			earliest = candidate
+		}
+	}
+	if !earliest.IsZero() {
+		return earliest
+	}
+
+	// skipped wall clock, read it with the offset before the gap
+	return naive.Add(-time.Duration(smallest) * time.Second).In(loc)
+}
+
+// floating returns the wall clock of t as a UTC time, the inverse of wallClock
+func floating(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
@@ -0,0 +1,72 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWallClock(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sydney, err := time.LoadLocation("Australia/Sydney")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		wall time.Time
+		loc  *time.Location
+		want time.Time
+	}{
+		{
+			name: "regular wall clock",
+			wall: time.Date(2025, 7, 21, 9, 0, 0, 0, time.UTC),
+			loc:  berlin,
+			want: time.Date(2025, 7, 21, 7, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "skipped wall clock is shifted forward by the gap",
+			wall: time.Date(2025, 3, 30, 2, 30, 0, 0, time.UTC),
+			loc:  berlin,
+			want: time.Date(2025, 3, 30, 1, 30, 0, 0, time.UTC), // 03:30 CEST
+		},
+		{
+			name: "repeated wall clock resolves to the earlier occurrence",
+			wall: time.Date(2025, 10, 26, 2, 30, 0, 0, time.UTC),
+			loc:  berlin,
+			want: time.Date(2025, 10, 26, 0, 30, 0, 0, time.UTC), // 02:30 CEST
+		},
+		{
+			name: "wall clock right after the repeated hour",
+			wall: time.Date(2025, 10, 26, 3, 0, 0, 0, time.UTC),
+			loc:  berlin,
+			want: time.Date(2025, 10, 26, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "skipped wall clock in the southern hemisphere",
+			wall: time.Date(2025, 10, 5, 2, 15, 0, 0, time.UTC),
+			loc:  sydney,
+			want: time.Date(2025, 10, 4, 16, 15, 0, 0, time.UTC), // 03:15 AEDT
+		},
+		{
+			name: "repeated wall clock in the southern hemisphere",
+			wall: time.Date(2025, 4, 6, 2, 15, 0, 0, time.UTC),
+			loc:  sydney,
+			want: time.Date(2025, 4, 5, 15, 15, 0, 0, time.UTC), // 02:15 AEDT
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := wallClock(tt.wall, tt.loc)
+			if !got.Equal(tt.want) {
+				t.Errorf("wallClock() = %v, want %v", got, tt.want.In(tt.loc))
+			}
+			if got.Location() != tt.loc {
+				t.Errorf("wallClock() location = %v, want %v", got.Location(), tt.loc)
+			}
+		})
+	}
+}
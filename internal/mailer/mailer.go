@@ -1,27 +1,78 @@
 package mailer
 
 import (
+	"errors"
 	"fmt"
-	"net/smtp"
-	"strings"
+	"net/mail"
 
 	"github.com/elangreza/scheduler/config"
+	"github.com/elangreza/scheduler/internal"
 )
 
-func sendMail(cfg *config.Config, to []string, cc []string, subject, message string) error {
-	body := "From: Scheduler\n" +
-		"To: " + strings.Join(to, ",") + "\n" +
-		"Cc: " + strings.Join(cc, ",") + "\n" +
-		"Subject: " + subject + "\n\n" +
-		message
+// Mailer sends emails through the SMTP server from the config, reusing its
+// connections across emails sent in a burst
+type Mailer struct {
+	from  *mail.Address
+	pool  *pool
+	clock internal.Clock
+}
+
+// ErrMissingSender is returned by Send when neither SMTP_FROM nor
+// SMTP_AUTH_EMAIL is set. Deployments only notifying through webhooks or logs
+// do not need either.
+var ErrMissingSender = errors.New("missing sender address, set SMTP_FROM or SMTP_AUTH_EMAIL")
+
+// NewMailer creates a mailer sending from SMTP_FROM, or from the SMTP
+// account as "Scheduler" when it is empty
+func NewMailer(cfg *config.Config, clock internal.Clock) (*Mailer, error) {
+	from := &mail.Address{Name: "Scheduler", Address: cfg.SmtpAuthEmail}
+	if cfg.SmtpFrom != "" {
+		var err error
+		from, err = mail.ParseAddress(cfg.SmtpFrom)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SMTP_FROM %q: %v", cfg.SmtpFrom, err)
+		}
+	}
+
+	transport, err := newTransport(cfg.SmtpHost, cfg.SmtpPort, cfg.SmtpTLS, cfg.SmtpTLSSkipVerify, cfg.SmtpCAFile, cfg.SmtpAuth, cfg.SmtpAuthEmail, cfg.SmtpAuthPassword, cfg.SmtpCommandTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Mailer{
+		from:  from,
+		pool:  newPool(transport.dial, cfg.SmtpPoolSize, cfg.SmtpIdleTimeout, clock),
+		clock: clock,
+	}, nil
+}
 
-	auth := smtp.PlainAuth("", cfg.SmtpAuthEmail, cfg.SmtpAuthPassword, cfg.SmtpHost)
-	smtpAddr := fmt.Sprintf("%s:%d", cfg.SmtpHost, cfg.SmtpPort)
+func (m *Mailer) Send(msg Message) error {
+	if m.from.Address == "" {
+		return ErrMissingSender
+	}
 
-	err := smtp.SendMail(smtpAddr, auth, cfg.SmtpAuthEmail, append(to, cc...), []byte(body))
+	recipients, err := msg.Recipients()
 	if err != nil {
 		return err
 	}
 
-	return nil
+	id := msg.ID
+	if id == "" {
+		id, err = NewMessageID()
+		if err != nil {
+			return err
+		}
+	}
+
+	body, err := msg.Build(m.from, m.clock.Now(), messageID(id, m.from))
+	if err != nil {
+		return err
+	}
+
+	return m.pool.send(m.from.Address, recipients, body)
+}
+
+// Close closes the idle connections to the SMTP server
+func (m *Mailer) Close() {
+	m.pool.close()
 }
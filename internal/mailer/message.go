@@ -0,0 +1,225 @@
+package mailer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+type (
+	// Message is an email, sent as multipart/alternative when it has both a
+	// text and an html body
+	Message struct {
+		To, Cc      []string // addresses, e.g., "Ops <ops@example.com>"
+		Subject     string
+		Text        string
+		HTML        string
+		Attachments []Attachment
+
+		// ID is the left part of the Message-ID, a random one is used when it
+		// is empty. Retries of a message keep its ID, so receivers can drop
+		// the duplicates of a message sent twice.
+		ID string
+	}
+
+	Attachment struct {
+		Filename    string
+		ContentType string // e.g., "text/calendar; method=PUBLISH"
+		Data        []byte
+	}
+
+	// part is a MIME entity, its header and encoded body
+	part struct {
+		header textproto.MIMEHeader
+		body   []byte
+	}
+)
+
+// Recipients returns the envelope addresses of the To and Cc recipients
+func (m Message) Recipients() ([]string, error) {
+	var recipients []string
+	for _, recipient := range append(append([]string{}, m.To...), m.Cc...) {
+		address, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient %q: %v", recipient, err)
+		}
+		recipients = append(recipients, address.Address)
+	}
+	return recipients, nil
+}
+
+// Build encodes the message from from, with CRLF line endings as SMTP
+// expects. Non-ASCII subjects and names are RFC 2047 encoded, bodies are
+// quoted-printable and attachments base64.
+func (m Message) Build(from *mail.Address, date time.Time, messageID string) ([]byte, error) {
+	if len(m.To) == 0 {
+		return nil, fmt.Errorf("message has no recipients")
+	}
+
+	to, err := addressList(m.To)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	writeHeader(&b, "From", from.String())
+	writeHeader(&b, "To", to)
+	if len(m.Cc) > 0 {
+		cc, err := addressList(m.Cc)
+		if err != nil {
+			return nil, err
+		}
+		writeHeader(&b, "Cc", cc)
+	}
+	writeHeader(&b, "Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	writeHeader(&b, "Date", date.Format(time.RFC1123Z))
+	writeHeader(&b, "Message-ID", messageID)
+	writeHeader(&b, "MIME-Version", "1.0")
+
+	writePart(&b, m.body())
+
+	return b.Bytes(), nil
+}
+
+// body returns the MIME entity of the bodies and attachments of the message
+func (m Message) body() part {
+	var bodies []part
+	if m.Text != "" || m.HTML == "" {
+		bodies = append(bodies, textPart("text/plain", m.Text))
+	}
+	if m.HTML != "" {
+		bodies = append(bodies, textPart("text/html", m.HTML))
+	}
+
+	body := bodies[0]
+	if len(bodies) > 1 {
+		body = multipartPart("alternative", bodies)
+	}
+	if len(m.Attachments) == 0 {
+		return body
+	}
+
+	parts := []part{body}
+	for _, attachment := range m.Attachments {
+		parts = append(parts, attachmentPart(attachment))
+	}
+	return multipartPart("mixed", parts)
+}
+
+func textPart(contentType, text string) part {
+	var b bytes.Buffer
+	w := quotedprintable.NewWriter(&b)
+	w.Write([]byte(text))
+	w.Close()
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Type", mime.FormatMediaType(contentType, map[string]string{"charset": "utf-8"}))
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+	return part{header: header, body: b.Bytes()}
+}
+
+func attachmentPart(attachment Attachment) part {
+	contentType := attachment.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(attachment.Filename))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	if mediaType, params, err := mime.ParseMediaType(contentType); err == nil {
+		params["name"] = attachment.Filename
+		contentType = mime.FormatMediaType(mediaType, params)
+	}
+
+	// base64 lines are at most 76 characters
+	encoded := base64.StdEncoding.EncodeToString(attachment.Data)
+	var b bytes.Buffer
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded)
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Transfer-Encoding", "base64")
+	header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+	return part{header: header, body: b.Bytes()}
+}
+
+// multipartPart returns the multipart entity of subtype holding parts
+func multipartPart(subtype string, parts []part) part {
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+	for _, p := range parts {
+		// writes to a bytes.Buffer do not fail
+		pw, _ := w.CreatePart(p.header)
+		pw.Write(p.body)
+	}
+	w.Close()
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Type", mime.FormatMediaType("multipart/"+subtype, map[string]string{"boundary": w.Boundary()}))
+	return part{header: header, body: b.Bytes()}
+}
+
+// writePart writes the header of the top level entity and its body
+func writePart(b *bytes.Buffer, p part) {
+	keys := make([]string, 0, len(p.header))
+	for key := range p.header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		writeHeader(b, key, p.header.Get(key))
+	}
+	b.WriteString("\r\n")
+	b.Write(p.body)
+}
+
+func writeHeader(b *bytes.Buffer, key, value string) {
+	b.WriteString(key + ": " + value + "\r\n")
+}
+
+// addressList formats addresses for a To or Cc header, encoding non-ASCII
+// names
+func addressList(addresses []string) (string, error) {
+	list := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		parsed, err := mail.ParseAddress(address)
+		if err != nil {
+			return "", fmt.Errorf("invalid recipient %q: %v", address, err)
+		}
+		list = append(list, parsed.String())
+	}
+	return strings.Join(list, ", "), nil
+}
+
+// NewMessageID returns a random ID for a message
+func NewMessageID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// messageID returns the Message-ID of id in the domain of from
+func messageID(id string, from *mail.Address) string {
+	domain := "localhost"
+	if at := strings.LastIndex(from.Address, "@"); at >= 0 {
+		domain = from.Address[at+1:]
+	}
+	return fmt.Sprintf("<%s@%s>", id, domain)
+}
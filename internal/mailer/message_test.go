@@ -0,0 +1,179 @@
+package mailer
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMessage_Build(t *testing.T) {
+	from := &mail.Address{Name: "Scheduler", Address: "scheduler@example.com"}
+	date := time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		msg  Message
+		// want are the media types of the leaf parts, in order
+		want []string
+	}{
+		{
+			name: "text",
+			msg:  Message{To: []string{"ops@example.com"}, Subject: "water the plants", Text: "every pot"},
+			want: []string{"text/plain"},
+		},
+		{
+			name: "text and html",
+			msg:  Message{To: []string{"ops@example.com"}, Subject: "water the plants", Text: "every pot", HTML: "<p>every pot</p>"},
+			want: []string{"text/plain", "text/html"},
+		},
+		{
+			name: "attachment",
+			msg: Message{
+				To:          []string{"ops@example.com"},
+				Subject:     "water the plants",
+				Text:        "every pot",
+				HTML:        "<p>every pot</p>",
+				Attachments: []Attachment{{Filename: "invite.ics", ContentType: "text/calendar; method=PUBLISH", Data: []byte("BEGIN:VCALENDAR\r\n")}},
+			},
+			want: []string{"text/plain", "text/html", "text/calendar"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := tt.msg.Build(from, date, "<1@example.com>")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Contains(bytes.ReplaceAll(raw, []byte("\r\n"), nil), []byte("\n")) {
+				t.Errorf("Message.Build() has bare LF line endings")
+			}
+
+			msg, err := mail.ReadMessage(bytes.NewReader(raw))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for key, want := range map[string]string{
+				"From":         `"Scheduler" <scheduler@example.com>`,
+				"To":           "<ops@example.com>",
+				"Date":         "Mon, 02 Jun 2025 09:00:00 +0000",
+				"Message-Id":   "<1@example.com>",
+				"Mime-Version": "1.0",
+			} {
+				if got := msg.Header.Get(key); got != want {
+					t.Errorf("Message.Build() header %s = %q, want %q", key, got, want)
+				}
+			}
+			if _, ok := msg.Header["Cc"]; ok {
+				t.Errorf("Message.Build() has a Cc header without Cc recipients")
+			}
+
+			var got []string
+			walkParts(t, msg.Header, msg.Body, func(mediaType string, body []byte) {
+				got = append(got, mediaType)
+			})
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("Message.Build() parts = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("encoded headers", func(t *testing.T) {
+		msg := Message{
+			To:      []string{"Jürgen <jurgen@example.com>"},
+			Cc:      []string{"ops@example.com"},
+			Subject: "Blumen gießen",
+			Text:    "Jeden Topf gießen",
+		}
+		raw, err := msg.Build(from, date, "<1@example.com>")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Contains(raw, []byte("Subject: =?utf-8?q?Blumen_gie=C3=9Fen?=\r\n")) {
+			t.Errorf("Message.Build() did not encode the subject:\n%s", raw)
+		}
+
+		parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+		if err != nil || subject != msg.Subject {
+			t.Errorf("Message.Build() subject decodes to %q, %v", subject, err)
+		}
+		to, err := parsed.Header.AddressList("To")
+		if err != nil || to[0].Name != "Jürgen" {
+			t.Errorf("Message.Build() To = %v, %v", to, err)
+		}
+		if parsed.Header.Get("Cc") != "<ops@example.com>" {
+			t.Errorf("Message.Build() Cc = %q", parsed.Header.Get("Cc"))
+		}
+
+		var body []byte
+		walkParts(t, parsed.Header, parsed.Body, func(mediaType string, b []byte) {
+			body = b
+		})
+		if string(body) != msg.Text {
+			t.Errorf("Message.Build() body decodes to %q, want %q", body, msg.Text)
+		}
+	})
+
+	t.Run("invalid recipient", func(t *testing.T) {
+		if _, err := (Message{To: []string{"not an address"}}).Build(from, date, "<1@example.com>"); err == nil {
+			t.Errorf("Message.Build() accepted an invalid recipient")
+		}
+		if _, err := (Message{}).Build(from, date, "<1@example.com>"); err == nil {
+			t.Errorf("Message.Build() accepted a message without recipients")
+		}
+	})
+}
+
+func TestMessage_Recipients(t *testing.T) {
+	msg := Message{To: []string{"Ops <ops@example.com>"}, Cc: []string{"dev@example.com"}}
+	got, err := msg.Recipients()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, " ") != "ops@example.com dev@example.com" {
+		t.Errorf("Message.Recipients() = %v", got)
+	}
+}
+
+// walkParts calls fn with the media type and body of every leaf part of an
+// entity, quoted-printable bodies are decoded
+func walkParts(t *testing.T, header interface{ Get(string) string }, body io.Reader, fn func(mediaType string, body []byte)) {
+	t.Helper()
+
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		if header.Get("Content-Transfer-Encoding") == "quoted-printable" {
+			body = quotedprintable.NewReader(body)
+		}
+		data, err := io.ReadAll(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fn(mediaType, data)
+		return
+	}
+
+	reader := multipart.NewReader(body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		walkParts(t, part.Header, part, fn)
+	}
+}
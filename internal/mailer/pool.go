@@ -0,0 +1,153 @@
+package mailer
+
+import (
+	"net/smtp"
+	"sync"
+	"time"
+
+	"github.com/elangreza/scheduler/internal"
+)
+
+type (
+	// pool keeps the connections of sent emails open for idleTimeout, so the
+	// emails of a burst of due schedules share a few connections instead of
+	// opening one each
+	pool struct {
+		dial        func() (*smtp.Client, error)
+		size        int
+		idleTimeout time.Duration
+		clock       internal.Clock
+
+		mu   sync.Mutex
+		idle []idleConn
+	}
+
+	idleConn struct {
+		client *smtp.Client
+		since  time.Time
+	}
+)
+
+func newPool(dial func() (*smtp.Client, error), size int, idleTimeout time.Duration, clock internal.Clock) *pool {
+	return &pool{dial: dial, size: size, idleTimeout: idleTimeout, clock: clock}
+}
+
+// send sends an email on an idle connection, or a new one when none is
+// left. A connection failing to send is closed instead of going back to the
+// pool.
+func (p *pool) send(from string, recipients []string, body []byte) error {
+	client, err := p.get()
+	if err != nil {
+		return err
+	}
+
+	if err := deliver(client, from, recipients, body); err != nil {
+		client.Close()
+		return err
+	}
+
+	p.put(client)
+	return nil
+}
+
+// get returns the most recently used live idle connection, closing the
+// expired ones, or dials a new connection
+func (p *pool) get() (*smtp.Client, error) {
+	for {
+		p.mu.Lock()
+		expired := p.expire()
+		var conn idleConn
+		if len(p.idle) > 0 {
+			conn = p.idle[len(p.idle)-1]
+			p.idle = p.idle[:len(p.idle)-1]
+		}
+		p.mu.Unlock()
+
+		quit(expired)
+		if conn.client == nil {
+			return p.dial()
+		}
+
+		// the server may have closed the connection while it was idle
+		if err := conn.client.Reset(); err != nil {
+			conn.client.Close()
+			continue
+		}
+		return conn.client, nil
+	}
+}
+
+func (p *pool) put(client *smtp.Client) {
+	p.mu.Lock()
+	expired := p.expire()
+	if len(p.idle) >= p.size {
+		expired = append(expired, client)
+	} else {
+		p.idle = append(p.idle, idleConn{client: client, since: p.clock.Now()})
+	}
+	p.mu.Unlock()
+
+	quit(expired)
+}
+
+// expire removes the connections idle for longer than idleTimeout and
+// returns them to be closed once p.mu is released, p.mu must be held
+func (p *pool) expire() []*smtp.Client {
+	var expired []*smtp.Client
+	live := p.idle[:0]
+	for _, conn := range p.idle {
+		if p.clock.Now().Sub(conn.since) > p.idleTimeout {
+			expired = append(expired, conn.client)
+			continue
+		}
+		live = append(live, conn)
+	}
+	p.idle = live
+	return expired
+}
+
+// close closes the idle connections
+func (p *pool) close() {
+	p.mu.Lock()
+	var idle []*smtp.Client
+	for _, conn := range p.idle {
+		idle = append(idle, conn.client)
+	}
+	p.idle = nil
+	p.mu.Unlock()
+
+	quit(idle)
+}
+
+// quit ends the conversations of clients, it blocks up to the command
+// timeout of each connection so p.mu must not be held. Quit leaves the
+// connection open when the server does not answer, it is closed then.
+func quit(clients []*smtp.Client) {
+	for _, client := range clients {
+		if err := client.Quit(); err != nil {
+			client.Close()
+		}
+	}
+}
+
+// deliver sends an email on an open connection
+func deliver(client *smtp.Client, from string, recipients []string, body []byte) error {
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, recipient := range recipients {
+		if err := client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
@@ -0,0 +1,225 @@
+package mailer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// TLSAuto upgrades connections with STARTTLS when the server offers it
+	TLSAuto = "auto"
+	// TLSStartTLS requires the server to upgrade connections with STARTTLS
+	TLSStartTLS = "starttls"
+	// TLSImplicit connects with TLS, usually to port 465
+	TLSImplicit = "tls"
+	// TLSNone never encrypts connections
+	TLSNone = "none"
+
+	AuthPlain   = "plain"
+	AuthLogin   = "login"
+	AuthCRAMMD5 = "cram-md5"
+	AuthNone    = "none"
+
+	dialTimeout = 30 * time.Second
+	// defaultCommandTimeout bounds the reads and writes of connections when
+	// no command timeout is given
+	defaultCommandTimeout = time.Minute
+)
+
+// transport opens authenticated connections to an SMTP server
+type transport struct {
+	addr           string
+	tlsMode        string
+	tlsConfig      *tls.Config
+	auth           smtp.Auth
+	commandTimeout time.Duration
+}
+
+// newTransport checks the TLS mode and auth mechanism of the connections to
+// host. caFile holds the PEM certificates trusted in addition to the system
+// ones, skipVerify accepts any certificate and is meant for development.
+func newTransport(host string, port int, tlsMode string, skipVerify bool, caFile, authMechanism, username, password string, commandTimeout time.Duration) (*transport, error) {
+	t := &transport{
+		addr:    net.JoinHostPort(host, strconv.Itoa(port)),
+		tlsMode: strings.ToLower(tlsMode),
+		tlsConfig: &tls.Config{
+			ServerName:         host,
+			InsecureSkipVerify: skipVerify,
+		},
+		commandTimeout: commandTimeout,
+	}
+	if t.commandTimeout <= 0 {
+		t.commandTimeout = defaultCommandTimeout
+	}
+
+	switch t.tlsMode {
+	case "":
+		t.tlsMode = TLSAuto
+	case TLSAuto, TLSStartTLS, TLSImplicit, TLSNone:
+	default:
+		return nil, fmt.Errorf("invalid SMTP TLS mode %q, must be one of %s, %s, %s or %s", tlsMode, TLSAuto, TLSStartTLS, TLSImplicit, TLSNone)
+	}
+
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("read SMTP CA file: %v", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("SMTP CA file %s has no PEM certificates", caFile)
+		}
+		t.tlsConfig.RootCAs = pool
+	}
+
+	mechanism := strings.ToLower(authMechanism)
+	if mechanism == "" {
+		mechanism = AuthNone
+		if username != "" {
+			mechanism = AuthPlain
+		}
+	}
+	switch mechanism {
+	case AuthPlain:
+		t.auth = smtp.PlainAuth("", username, password, host)
+	case AuthLogin:
+		t.auth = &loginAuth{host: host, username: username, password: password}
+	case AuthCRAMMD5:
+		t.auth = smtp.CRAMMD5Auth(username, password)
+	case AuthNone:
+	default:
+		return nil, fmt.Errorf("invalid SMTP auth mechanism %q, must be one of %s, %s, %s or %s", authMechanism, AuthPlain, AuthLogin, AuthCRAMMD5, AuthNone)
+	}
+
+	return t, nil
+}
+
+// dial opens a connection ready to send mail, encrypted as the TLS mode
+// requires and authenticated
+func (t *transport) dial() (*smtp.Client, error) {
+	dialer := &net.Dialer{Timeout: dialTimeout}
+
+	raw, err := dialer.Dial("tcp", t.addr)
+	if err != nil {
+		return nil, err
+	}
+
+	// the deadline is set under TLS too, STARTTLS and implicit TLS wrap conn
+	var conn net.Conn = &deadlineConn{Conn: raw, timeout: t.commandTimeout}
+	if t.tlsMode == TLSImplicit {
+		tlsConn := tls.Client(conn, t.tlsConfig)
+		if err := tlsConn.Handshake(); err != nil {
+			raw.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	client, err := smtp.NewClient(conn, t.tlsConfig.ServerName)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if err := t.handshake(client); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// deadlineConn sets the deadline of every read and write, so a server
+// stalling in the middle of a conversation fails the command
+type deadlineConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+func (c *deadlineConn) Read(b []byte) (int, error) {
+	if err := c.Conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+		return 0, err
+	}
+	return c.Conn.Read(b)
+}
+
+func (c *deadlineConn) Write(b []byte) (int, error) {
+	if err := c.Conn.SetWriteDeadline(time.Now().Add(c.timeout)); err != nil {
+		return 0, err
+	}
+	return c.Conn.Write(b)
+}
+
+func (t *transport) handshake(client *smtp.Client) error {
+	if err := client.Hello("localhost"); err != nil {
+		return err
+	}
+
+	if t.tlsMode == TLSAuto || t.tlsMode == TLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(t.tlsConfig); err != nil {
+				return err
+			}
+		} else if t.tlsMode == TLSStartTLS {
+			return fmt.Errorf("smtp server %s does not support STARTTLS", t.addr)
+		}
+	}
+
+	if t.auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return fmt.Errorf("smtp server %s does not support AUTH", t.addr)
+		}
+		if err := client.Auth(t.auth); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loginAuth is the LOGIN mechanism, sending the username and password as
+// answers to the server prompts. Like smtp.PlainAuth, it refuses to send
+// credentials over unencrypted connections, except to localhost.
+type loginAuth struct {
+	host, username, password string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+
+	switch prompt := strings.ToLower(strings.TrimSpace(string(fromServer))); {
+	case strings.HasPrefix(prompt, "username"):
+		return []byte(a.username), nil
+	case strings.HasPrefix(prompt, "password"):
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected LOGIN prompt %q", fromServer)
+	}
+}
+
+func isLocalhost(name string) bool {
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
+}
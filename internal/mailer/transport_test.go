@@ -0,0 +1,474 @@
+package mailer
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/elangreza/scheduler/config"
+	"github.com/elangreza/scheduler/internal"
+)
+
+const (
+	testUsername = "scheduler@example.com"
+	testPassword = "secret"
+)
+
+// smtpServer is a fake SMTP server recording the connections and emails it
+// receives
+type smtpServer struct {
+	listener  net.Listener
+	tlsConfig *tls.Config
+	// startTLS offers STARTTLS on plain connections
+	startTLS bool
+
+	mu     sync.Mutex
+	conns  int
+	quits  int
+	auths  []string
+	emails []string
+}
+
+func newSMTPServer(t *testing.T, tlsConfig *tls.Config, implicitTLS, startTLS bool) *smtpServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if implicitTLS {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+	s := &smtpServer{listener: listener, tlsConfig: tlsConfig, startTLS: startTLS}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns++
+			s.mu.Unlock()
+			go s.serve(conn)
+		}
+	}()
+
+	return s
+}
+
+func (s *smtpServer) config() *config.Config {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+	return &config.Config{
+		SmtpHost:        host,
+		SmtpPort:        p,
+		SmtpTLS:         TLSNone,
+		SmtpFrom:        "Scheduler <scheduler@example.com>",
+		SmtpPoolSize:    2,
+		SmtpIdleTimeout: time.Minute,
+	}
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	text := textproto.NewConn(conn)
+	_, isTLS := conn.(*tls.Conn)
+	text.PrintfLine("220 fake ESMTP")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		command, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(command) {
+		case "EHLO", "HELO":
+			extensions := []string{"fake"}
+			if s.startTLS && !isTLS {
+				extensions = append(extensions, "STARTTLS")
+			}
+			extensions = append(extensions, "AUTH PLAIN LOGIN CRAM-MD5")
+			for i, extension := range extensions {
+				separator := "-"
+				if i == len(extensions)-1 {
+					separator = " "
+				}
+				text.PrintfLine("250%s%s", separator, extension)
+			}
+		case "STARTTLS":
+			text.PrintfLine("220 ready")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, isTLS = tlsConn, true
+			text = textproto.NewConn(conn)
+		case "AUTH":
+			mechanism, ok := s.auth(text, arg)
+			if !ok {
+				text.PrintfLine("535 invalid credentials")
+				continue
+			}
+			s.mu.Lock()
+			s.auths = append(s.auths, mechanism)
+			s.mu.Unlock()
+			text.PrintfLine("235 authenticated")
+		case "DATA":
+			text.PrintfLine("354 go ahead")
+			data, err := io.ReadAll(text.DotReader())
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.emails = append(s.emails, string(data))
+			s.mu.Unlock()
+			text.PrintfLine("250 queued")
+		case "QUIT":
+			s.mu.Lock()
+			s.quits++
+			s.mu.Unlock()
+			text.PrintfLine("221 bye")
+			return
+		default:
+			text.PrintfLine("250 ok")
+		}
+	}
+}
+
+// auth runs the AUTH exchange of arg, it returns the mechanism and whether
+// the credentials are valid
+func (s *smtpServer) auth(text *textproto.Conn, arg string) (string, bool) {
+	mechanism, initial, _ := strings.Cut(arg, " ")
+	readAnswer := func(challenge string) string {
+		text.PrintfLine("334 %s", base64.StdEncoding.EncodeToString([]byte(challenge)))
+		line, _ := text.ReadLine()
+		answer, _ := base64.StdEncoding.DecodeString(line)
+		return string(answer)
+	}
+
+	switch mechanism {
+	case "PLAIN":
+		answer, _ := base64.StdEncoding.DecodeString(initial)
+		return mechanism, string(answer) == "\x00"+testUsername+"\x00"+testPassword
+	case "LOGIN":
+		username := readAnswer("Username:")
+		password := readAnswer("Password:")
+		return mechanism, username == testUsername && password == testPassword
+	case "CRAM-MD5":
+		challenge := "<1.1@fake>"
+		username, digest, _ := strings.Cut(readAnswer(challenge), " ")
+		mac := hmac.New(md5.New, []byte(testPassword))
+		mac.Write([]byte(challenge))
+		return mechanism, username == testUsername && digest == hex.EncodeToString(mac.Sum(nil))
+	default:
+		return mechanism, false
+	}
+}
+
+func (s *smtpServer) stats() (conns, quits int, auths, emails []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.conns, s.quits, append([]string{}, s.auths...), append([]string{}, s.emails...)
+}
+
+// waitQuits waits for the server to receive want QUIT commands, they arrive
+// after the client returned
+func (s *smtpServer) waitQuits(t *testing.T, want int) {
+	t.Helper()
+
+	for i := 0; i < 100; i++ {
+		if _, quits, _, _ := s.stats(); quits >= want {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("smtp server received less than %d QUIT", want)
+}
+
+// testCertificate returns a self-signed certificate for 127.0.0.1 and the
+// path of its PEM file
+func testCertificate(t *testing.T) (tls.Certificate, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "fake smtp"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, caFile
+}
+
+func testMessage(i int) Message {
+	return Message{To: []string{"ops@example.com"}, Subject: fmt.Sprintf("email %d", i), Text: "every pot"}
+}
+
+func TestMailer_Send_reusesConnections(t *testing.T) {
+	server := newSMTPServer(t, nil, false, false)
+	clock := internal.NewFakeClock(time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC))
+	m, err := NewMailer(server.config(), clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := m.Send(testMessage(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if conns, _, auths, emails := server.stats(); conns != 1 || len(emails) != 3 || len(auths) != 0 {
+		t.Errorf("a burst of 3 emails used %d connections and sent %d emails with auths %v, want 1 connection, 3 emails, no auth", conns, len(emails), auths)
+	}
+
+	// the idle connection expires, the next email opens a new one
+	clock.Advance(2 * time.Minute)
+	if err := m.Send(testMessage(3)); err != nil {
+		t.Fatal(err)
+	}
+	server.waitQuits(t, 1)
+	if conns, _, _, emails := server.stats(); conns != 2 || len(emails) != 4 {
+		t.Errorf("an email after the idle timeout used %d connections in total, want 2", conns)
+	}
+
+	m.Close()
+	server.waitQuits(t, 2)
+}
+
+func TestMailer_Send_auth(t *testing.T) {
+	tests := []struct {
+		name      string
+		mechanism string
+		password  string
+		want      string
+		wantErr   bool
+	}{
+		{name: "default", mechanism: "", password: testPassword, want: "PLAIN"},
+		{name: "plain", mechanism: AuthPlain, password: testPassword, want: "PLAIN"},
+		{name: "login", mechanism: AuthLogin, password: testPassword, want: "LOGIN"},
+		{name: "cram-md5", mechanism: AuthCRAMMD5, password: testPassword, want: "CRAM-MD5"},
+		{name: "wrong password", mechanism: AuthLogin, password: "guess", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newSMTPServer(t, nil, false, false)
+			cfg := server.config()
+			cfg.SmtpAuth = tt.mechanism
+			cfg.SmtpAuthEmail = testUsername
+			cfg.SmtpAuthPassword = tt.password
+			m, err := NewMailer(cfg, internal.SystemClock{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer m.Close()
+
+			err = m.Send(testMessage(0))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Mailer.Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if _, _, auths, _ := server.stats(); !tt.wantErr && strings.Join(auths, " ") != tt.want {
+				t.Errorf("Mailer.Send() authenticated with %v, want %s", auths, tt.want)
+			}
+		})
+	}
+}
+
+func TestMailer_Send_tls(t *testing.T) {
+	certificate, caFile := testCertificate(t)
+	serverTLS := &tls.Config{Certificates: []tls.Certificate{certificate}}
+
+	tests := []struct {
+		name        string
+		implicitTLS bool
+		startTLS    bool
+		mode        string
+		caFile      string
+		skipVerify  bool
+		wantErr     bool
+	}{
+		{name: "implicit tls", implicitTLS: true, mode: TLSImplicit, caFile: caFile},
+		{name: "starttls", startTLS: true, mode: TLSStartTLS, caFile: caFile},
+		{name: "auto upgrades", startTLS: true, mode: TLSAuto, caFile: caFile},
+		{name: "auto without starttls", mode: TLSAuto},
+		{name: "skip verify", implicitTLS: true, mode: TLSImplicit, skipVerify: true},
+		{name: "unknown certificate", implicitTLS: true, mode: TLSImplicit, wantErr: true},
+		{name: "starttls not offered", mode: TLSStartTLS, caFile: caFile, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newSMTPServer(t, serverTLS, tt.implicitTLS, tt.startTLS)
+			cfg := server.config()
+			cfg.SmtpTLS = tt.mode
+			cfg.SmtpCAFile = tt.caFile
+			cfg.SmtpTLSSkipVerify = tt.skipVerify
+			// credentials are only sent over TLS to other hosts than localhost
+			cfg.SmtpAuthEmail = testUsername
+			cfg.SmtpAuthPassword = testPassword
+			m, err := NewMailer(cfg, internal.SystemClock{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer m.Close()
+
+			err = m.Send(testMessage(0))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Mailer.Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if _, _, _, emails := server.stats(); !tt.wantErr && len(emails) != 1 {
+				t.Errorf("smtp server received %d emails, want 1", len(emails))
+			}
+		})
+	}
+}
+
+func TestNewMailer(t *testing.T) {
+	const from = "Scheduler <scheduler@example.com>"
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, []byte("not a certificate"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		cfg  config.Config
+	}{
+		{name: "invalid tls mode", cfg: config.Config{SmtpFrom: from, SmtpTLS: "ssl"}},
+		{name: "invalid auth", cfg: config.Config{SmtpFrom: from, SmtpAuth: "xoauth2"}},
+		{name: "missing ca file", cfg: config.Config{SmtpFrom: from, SmtpCAFile: filepath.Join(t.TempDir(), "missing.pem")}},
+		{name: "invalid ca file", cfg: config.Config{SmtpFrom: from, SmtpCAFile: caFile}},
+		{name: "invalid from", cfg: config.Config{SmtpFrom: "scheduler"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewMailer(&tt.cfg, internal.SystemClock{}); err == nil {
+				t.Errorf("NewMailer() accepted %s", tt.name)
+			}
+		})
+	}
+}
+
+func TestMailer_Send_missingSender(t *testing.T) {
+	server := newSMTPServer(t, nil, false, false)
+	cfg := server.config()
+	cfg.SmtpFrom = ""
+
+	// the mailer is created without a sender, as webhook and log reminders
+	// do not need one, and fails sending
+	m, err := NewMailer(cfg, internal.SystemClock{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	if err := m.Send(testMessage(0)); !errors.Is(err, ErrMissingSender) {
+		t.Errorf("Send() error = %v, want %v", err, ErrMissingSender)
+	}
+	if _, _, _, emails := server.stats(); len(emails) != 0 {
+		t.Errorf("smtp server received %d emails, want 0", len(emails))
+	}
+}
+
+func TestMailer_Send_stalledServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	// the server answers until the email is sent, then stops responding
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		text := textproto.NewConn(conn)
+		text.PrintfLine("220 stalling ESMTP")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+			switch verb, _, _ := strings.Cut(line, " "); strings.ToUpper(verb) {
+			case "EHLO":
+				text.PrintfLine("250 stalling")
+			case "MAIL", "RCPT":
+				text.PrintfLine("250 OK")
+			default:
+				// DATA is never answered
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+	m, err := NewMailer(&config.Config{
+		SmtpHost:           host,
+		SmtpPort:           p,
+		SmtpTLS:            TLSNone,
+		SmtpFrom:           "Scheduler <scheduler@example.com>",
+		SmtpPoolSize:       1,
+		SmtpIdleTimeout:    time.Minute,
+		SmtpCommandTimeout: 100 * time.Millisecond,
+	}, internal.SystemClock{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	done := make(chan error, 1)
+	go func() { done <- m.Send(testMessage(0)) }()
+	select {
+	case err := <-done:
+		var netErr net.Error
+		if !errors.As(err, &netErr) || !netErr.Timeout() {
+			t.Errorf("Send() error = %v, want a timeout", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Send() blocked on a stalled server")
+	}
+}
@@ -0,0 +1,41 @@
+package internal
+
+import "time"
+
+const (
+	// MisfireFireAll fires every missed run of a Reminder, one after another
+	MisfireFireAll = "fire_all"
+	// MisfireFireOnce fires a single run for all the missed runs of a
+	// Reminder
+	MisfireFireOnce = "fire_once"
+	// MisfireSkip drops the missed runs of a Reminder, it fires again at its
+	// next future run
+	MisfireSkip = "skip"
+)
+
+// Misfired reports whether the schedule was due more than threshold before
+// now, e.g., because the dispatcher was down. Retries, snoozes and
+// escalations are never misfired.
+func (s *Schedule) Misfired(now time.Time, threshold time.Duration) bool {
+	return s.Status == StatusCreated && s.Kind == ScheduleOccurrence && now.Sub(s.NotifyAt) > threshold
+}
+
+// MissedSince returns the occurrences of the Reminder following occurrence
+// whose run time is not after now, and the latest of them. The latest is the
+// zero time when no occurrence was missed.
+func (s *Reminder) MissedSince(occurrence, now time.Time) (int, time.Time) {
+	var (
+		missed int
+		latest time.Time
+	)
+
+	for next := s.GetNextRunAt(occurrence); !next.IsZero() && missed < maxOccurrenceSteps; next = s.GetNextRunAt(next) {
+		if s.RunAt(next).After(now) || !next.After(occurrence) {
+			break
+		}
+		missed++
+		latest, occurrence = next, next
+	}
+
+	return missed, latest
+}
@@ -0,0 +1,113 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSchedule_Misfired(t *testing.T) {
+	now := time.Date(2025, 7, 20, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		status   ActionStatus
+		notifyAt time.Time
+		want     bool
+	}{
+		{name: "on time", status: StatusCreated, notifyAt: now, want: false},
+		{name: "within threshold", status: StatusCreated, notifyAt: now.Add(-time.Minute), want: false},
+		{name: "past threshold", status: StatusCreated, notifyAt: now.Add(-time.Minute - time.Second), want: true},
+		{name: "retry", status: StatusFailed, notifyAt: now.Add(-time.Hour), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := NewSchedule(1, 1, tt.notifyAt)
+			schedule.Status = tt.status
+			if got := schedule.Misfired(now, time.Minute); got != tt.want {
+				t.Errorf("Schedule.Misfired() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReminder_MissedSince(t *testing.T) {
+	start := time.Date(2025, 7, 20, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		end        string
+		exceptions []ReminderException
+		repeat     string
+		occurrence time.Time
+		now        time.Time
+		wantMissed int
+		wantLatest time.Time
+	}{
+		{
+			name:       "nothing missed",
+			repeat:     "1h",
+			occurrence: start,
+			now:        start.Add(30 * time.Minute),
+			wantMissed: 0,
+			wantLatest: time.Time{},
+		},
+		{
+			name:       "missed hours",
+			repeat:     "1h",
+			occurrence: start,
+			now:        start.Add(3*time.Hour + 30*time.Minute),
+			wantMissed: 3,
+			wantLatest: start.Add(3 * time.Hour),
+		},
+		{
+			name:       "run at now is missed",
+			repeat:     "1h",
+			occurrence: start,
+			now:        start.Add(2 * time.Hour),
+			wantMissed: 2,
+			wantLatest: start.Add(2 * time.Hour),
+		},
+		{
+			name:       "stops at end time",
+			end:        "2025-07-20T11:00:00Z",
+			repeat:     "1h",
+			occurrence: start,
+			now:        start.Add(5 * time.Hour),
+			wantMissed: 2,
+			wantLatest: start.Add(2 * time.Hour),
+		},
+		{
+			name:       "one time reminder",
+			occurrence: start,
+			now:        start.Add(5 * time.Hour),
+			wantMissed: 0,
+			wantLatest: time.Time{},
+		},
+		{
+			name:   "overridden run not due yet",
+			repeat: "1h",
+			exceptions: []ReminderException{{
+				Kind:         ExceptionOverride,
+				OccurrenceAt: start.Add(2 * time.Hour),
+				RunAt:        start.Add(4 * time.Hour),
+			}},
+			occurrence: start,
+			now:        start.Add(2*time.Hour + 30*time.Minute),
+			wantMissed: 1,
+			wantLatest: start.Add(time.Hour),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reminder, err := NewReminder(1, start.Format(time.RFC3339), tt.end, tt.repeat, nil, withRecipient()...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			reminder.Exceptions = tt.exceptions
+
+			missed, latest := reminder.MissedSince(tt.occurrence, tt.now)
+			if missed != tt.wantMissed || !latest.Equal(tt.wantLatest) {
+				t.Errorf("Reminder.MissedSince() = %d %v, want %d %v", missed, latest, tt.wantMissed, tt.wantLatest)
+			}
+		})
+	}
+}
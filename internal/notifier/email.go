@@ -0,0 +1,201 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/elangreza/scheduler/internal"
+	"github.com/elangreza/scheduler/internal/emailtemplate"
+	"github.com/elangreza/scheduler/internal/mailer"
+)
+
+// snoozeOptions are the snooze links of an email
+var snoozeOptions = []struct {
+	label    string
+	duration time.Duration
+}{
+	{label: "15 minutes", duration: 15 * time.Minute},
+	{label: "1 hour", duration: time.Hour},
+	{label: "1 day", duration: 24 * time.Hour},
+}
+
+type (
+	// mailSender sends built emails
+	mailSender interface {
+		Send(msg mailer.Message) error
+	}
+
+	// templates renders emails with the template set of a task
+	templates interface {
+		Render(name string, data emailtemplate.Data) (*emailtemplate.Email, error)
+	}
+
+	// actionLinks builds the signed one-click links of a fired schedule
+	actionLinks interface {
+		AcknowledgeURL(scheduleID int64) string
+		SnoozeURL(scheduleID int64, d time.Duration) string
+	}
+
+	// recipientZones lists the quiet hours of recipients, recipients read
+	// times in the time zone of their quiet hours
+	recipientZones interface {
+		ListQuietHours(ctx context.Context) ([]internal.QuietHours, error)
+	}
+
+	// Email renders the email templates of the task for the recipients of the
+	// reminder, with links to acknowledge or snooze the schedule. Escalations
+	// are only acknowledged. Recipients are sent one email per time zone, so
+	// each reads the occurrence in their own.
+	Email struct {
+		mailer    mailSender
+		templates templates
+		links     actionLinks
+		zones     recipientZones
+		attachICS bool
+	}
+)
+
+// NewEmail creates an email notifier. Emails are rendered with the built-in
+// templates when templates is nil, have no links when links is nil, and show
+// times in the time zone of the reminder when zones is nil. With attachICS,
+// emails have the occurrence attached as an iCalendar event.
+func NewEmail(mailer mailSender, templates templates, links actionLinks, zones recipientZones, attachICS bool) *Email {
+	if templates == nil {
+		templates = emailtemplate.NewRegistry()
+	}
+	return &Email{mailer: mailer, templates: templates, links: links, zones: zones, attachICS: attachICS}
+}
+
+func (n *Email) Send(ctx context.Context, schedule *internal.Schedule, task internal.Task) error {
+	messages, err := n.Compose(ctx, schedule, task)
+	if err != nil {
+		return err
+	}
+
+	for _, msg := range messages {
+		if err := n.mailer.Send(msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Compose renders the emails of a schedule, one per time zone of its
+// recipients, each with an ID of its own
+func (n *Email) Compose(ctx context.Context, schedule *internal.Schedule, task internal.Task) ([]mailer.Message, error) {
+	if schedule.Reminder == nil || len(schedule.Reminder.Recipients) == 0 {
+		return nil, fmt.Errorf("schedule %d has no recipients", schedule.ID)
+	}
+
+	groups, err := n.zoneGroups(ctx, schedule.Reminder)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]mailer.Message, 0, len(groups))
+	for _, group := range groups {
+		data := n.data(schedule, task, group.location)
+		email, err := n.templates.Render(task.EmailTemplate, data)
+		if err != nil {
+			return nil, err
+		}
+
+		id, err := mailer.NewMessageID()
+		if err != nil {
+			return nil, err
+		}
+
+		msg := mailer.Message{
+			To:      group.recipients,
+			Subject: email.Subject,
+			Text:    email.Text,
+			HTML:    email.HTML,
+			ID:      id,
+		}
+		if n.attachICS {
+			msg.Attachments = append(msg.Attachments, mailer.Attachment{
+				Filename:    "invite.ics",
+				ContentType: "text/calendar; charset=utf-8; method=PUBLISH",
+				Data:        icsEvent(schedule, task, data.OccurrenceAt),
+			})
+		}
+		messages = append(messages, msg)
+	}
+
+	return messages, nil
+}
+
+// zoneGroup is the recipients of an email reading times in location
+type zoneGroup struct {
+	location   *time.Location
+	recipients []string
+}
+
+// zoneGroups groups the recipients of reminder by the time zone they read
+// times in, recipients without one read them in the time zone of reminder
+func (n *Email) zoneGroups(ctx context.Context, reminder *internal.Reminder) ([]zoneGroup, error) {
+	zones := make(map[string]*time.Location)
+	if n.zones != nil {
+		list, err := n.zones.ListQuietHours(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, quietHours := range list {
+			zones[quietHours.Recipient] = quietHours.Location()
+		}
+	}
+
+	var groups []zoneGroup
+	for _, recipient := range reminder.Recipients {
+		location := reminder.Location()
+		if address, err := internal.RecipientAddress(recipient); err == nil {
+			if zone, ok := zones[address]; ok {
+				location = zone
+			}
+		}
+
+		i := 0
+		for i < len(groups) && groups[i].location.String() != location.String() {
+			i++
+		}
+		if i == len(groups) {
+			groups = append(groups, zoneGroup{location: location})
+		}
+		groups[i].recipients = append(groups[i].recipients, recipient)
+	}
+
+	return groups, nil
+}
+
+func (n *Email) data(schedule *internal.Schedule, task internal.Task, location *time.Location) emailtemplate.Data {
+	occurrenceAt := schedule.OccurrenceAt
+	if occurrenceAt.IsZero() {
+		occurrenceAt = schedule.NotifyAt
+	}
+
+	data := emailtemplate.Data{
+		Task:         task,
+		Reminder:     *schedule.Reminder,
+		Schedule:     *schedule,
+		OccurrenceAt: occurrenceAt.In(location),
+		Escalated:    schedule.Step > 0,
+	}
+	if n.links == nil {
+		return data
+	}
+
+	data.Links = &emailtemplate.Links{Acknowledge: n.links.AcknowledgeURL(schedule.ID)}
+	// escalations can only be acknowledged
+	if schedule.Step > 0 {
+		return data
+	}
+	for _, option := range snoozeOptions {
+		data.Links.Snooze = append(data.Links.Snooze, emailtemplate.SnoozeLink{
+			Label: option.label,
+			URL:   n.links.SnoozeURL(schedule.ID, option.duration),
+		})
+	}
+	return data
+}
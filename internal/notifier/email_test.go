@@ -0,0 +1,192 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elangreza/scheduler/internal"
+	"github.com/elangreza/scheduler/internal/emailtemplate"
+	"github.com/elangreza/scheduler/internal/mailer"
+)
+
+type recordingMailer struct {
+	to          []string
+	subject     string
+	message     string
+	html        string
+	attachments []mailer.Attachment
+	// sent are the recipients of every email, one string per email
+	sent []string
+}
+
+func (m *recordingMailer) Send(msg mailer.Message) error {
+	m.to, m.subject, m.message, m.html, m.attachments = msg.To, msg.Subject, msg.Text, msg.HTML, msg.Attachments
+	m.sent = append(m.sent, strings.Join(msg.To, ","))
+	return nil
+}
+
+type fakeZones []internal.QuietHours
+
+func (z fakeZones) ListQuietHours(ctx context.Context) ([]internal.QuietHours, error) {
+	return z, nil
+}
+
+type fakeLinks struct{}
+
+func (fakeLinks) AcknowledgeURL(scheduleID int64) string {
+	return fmt.Sprintf("ack/%d", scheduleID)
+}
+
+func (fakeLinks) SnoozeURL(scheduleID int64, d time.Duration) string {
+	return fmt.Sprintf("snooze/%d/%s", scheduleID, d)
+}
+
+func TestEmail_Send(t *testing.T) {
+	task := internal.Task{Name: "water the plants", Description: "every pot"}
+	reminder := &internal.Reminder{Recipients: []string{"gardener@example.com"}}
+
+	tests := []struct {
+		name  string
+		kind  string
+		step  int
+		links actionLinks
+		want  string
+	}{
+		{
+			name:  "without links",
+			links: nil,
+			want:  "every pot",
+		},
+		{
+			name:  "with links",
+			links: fakeLinks{},
+			want: "every pot\n\n" +
+				"Acknowledge: ack/7\n" +
+				"Snooze for 15 minutes: snooze/7/15m0s\n" +
+				"Snooze for 1 hour: snooze/7/1h0m0s\n" +
+				"Snooze for 1 day: snooze/7/24h0m0s\n",
+		},
+		{
+			name:  "escalation",
+			kind:  internal.ScheduleEscalation,
+			step:  1,
+			links: fakeLinks{},
+			want: "Escalated, schedule 3 was not acknowledged.\n\n" +
+				"every pot\n\n" +
+				"Acknowledge: ack/7\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := &internal.Schedule{ID: 7, Kind: tt.kind, ParentID: 3, Step: tt.step, Reminder: reminder}
+			mailer := &recordingMailer{}
+			if err := NewEmail(mailer, nil, tt.links, nil, false).Send(context.Background(), schedule, task); err != nil {
+				t.Fatal(err)
+			}
+			if mailer.subject != task.Name || strings.Join(mailer.to, ",") != "gardener@example.com" {
+				t.Errorf("Email.Send() to %v subject %q", mailer.to, mailer.subject)
+			}
+			if mailer.message != tt.want {
+				t.Errorf("Email.Send() message = %q, want %q", mailer.message, tt.want)
+			}
+			if len(mailer.attachments) != 0 {
+				t.Errorf("Email.Send() attached %d files without attachICS", len(mailer.attachments))
+			}
+		})
+	}
+
+	t.Run("ics attachment", func(t *testing.T) {
+		schedule := &internal.Schedule{
+			ID:           7,
+			ReminderID:   2,
+			NotifyAt:     time.Date(2025, 6, 2, 9, 5, 0, 0, time.UTC),
+			OccurrenceAt: time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC),
+			Reminder:     reminder,
+		}
+		mailer := &recordingMailer{}
+		if err := NewEmail(mailer, nil, nil, nil, true).Send(context.Background(), schedule, task); err != nil {
+			t.Fatal(err)
+		}
+		if len(mailer.attachments) != 1 || mailer.attachments[0].Filename != "invite.ics" {
+			t.Fatalf("Email.Send() attachments = %v, want invite.ics", mailer.attachments)
+		}
+		ics := string(mailer.attachments[0].Data)
+		for _, line := range []string{"UID:reminder-2-20250602T090000Z@scheduler\r\n", "DTSTART:20250602T090000Z\r\n", "SUMMARY:water the plants\r\n"} {
+			if !strings.Contains(ics, line) {
+				t.Errorf("invite.ics has no line %q:\n%s", line, ics)
+			}
+		}
+	})
+
+	t.Run("without recipients", func(t *testing.T) {
+		err := NewEmail(&recordingMailer{}, nil, nil, nil, false).Send(context.Background(), &internal.Schedule{ID: 7, Reminder: &internal.Reminder{}}, task)
+		if err == nil {
+			t.Errorf("Email.Send() sent an email without recipients")
+		}
+	})
+}
+
+func TestEmail_Send_templates(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "body.html"), `<p>{{.Task.Description}}</p>`)
+	writeFile(t, filepath.Join(dir, "billing", "subject.txt"), "[Billing] {{.Task.Name}} at {{.OccurrenceAt.Format \"15:04 MST\"}}")
+	templates, err := emailtemplate.LoadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	berlin, err := internal.NewQuietHours("berlin@example.com", "22:00", "07:00", "Europe/Berlin", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	zones := fakeZones{*berlin}
+
+	reminder := &internal.Reminder{
+		Recipients: []string{"ops@example.com", "Berlin <BERLIN@example.com>", "dev@example.com"},
+		StartTime:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	schedule := &internal.Schedule{ID: 7, OccurrenceAt: time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC), Reminder: reminder}
+	task := internal.Task{Name: "pay rent", Description: "<monthly>", EmailTemplate: "billing"}
+
+	mailer := &recordingMailer{}
+	if err := NewEmail(mailer, templates, nil, zones, false).Send(context.Background(), schedule, task); err != nil {
+		t.Fatal(err)
+	}
+
+	// recipients are grouped by time zone, in the order of their first recipient
+	if want := []string{"ops@example.com,dev@example.com", "Berlin <BERLIN@example.com>"}; strings.Join(mailer.sent, " ") != strings.Join(want, " ") {
+		t.Errorf("Email.Send() sent to %v, want %v", mailer.sent, want)
+	}
+	if want := "[Billing] pay rent at 11:00 CEST"; mailer.subject != want {
+		t.Errorf("Email.Send() subject = %q, want %q", mailer.subject, want)
+	}
+	// the set falls back to the default text and html templates
+	if mailer.message != "<monthly>" {
+		t.Errorf("Email.Send() text = %q, want %q", mailer.message, "<monthly>")
+	}
+	if want := "<p>&lt;monthly&gt;</p>"; mailer.html != want {
+		t.Errorf("Email.Send() html = %q, want %q", mailer.html, want)
+	}
+
+	t.Run("unknown template", func(t *testing.T) {
+		task := internal.Task{Name: "pay rent", EmailTemplate: "missing"}
+		if err := NewEmail(&recordingMailer{}, templates, nil, nil, false).Send(context.Background(), schedule, task); err == nil {
+			t.Errorf("Email.Send() rendered an unknown template")
+		}
+	})
+}
+
+func writeFile(t *testing.T, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
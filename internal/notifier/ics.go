@@ -0,0 +1,59 @@
+package notifier
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/elangreza/scheduler/internal"
+)
+
+const icsDateTime = "20060102T150405Z"
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// icsEvent returns an iCalendar file with the occurrence the schedule
+// notifies as event. Every schedule of an occurrence, its snoozes and
+// escalations, has the same event UID, so calendars update a single event.
+func icsEvent(schedule *internal.Schedule, task internal.Task, occurrenceAt time.Time) []byte {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//elangreza//scheduler//EN",
+		"METHOD:PUBLISH",
+		"BEGIN:VEVENT",
+		fmt.Sprintf("UID:reminder-%d-%s@scheduler", schedule.ReminderID, occurrenceAt.UTC().Format(icsDateTime)),
+		"DTSTAMP:" + schedule.NotifyAt.UTC().Format(icsDateTime),
+		"DTSTART:" + occurrenceAt.UTC().Format(icsDateTime),
+		"SUMMARY:" + icsEscaper.Replace(task.Name),
+	}
+	if task.Description != "" {
+		lines = append(lines, "DESCRIPTION:"+icsEscaper.Replace(task.Description))
+	}
+	lines = append(lines, "END:VEVENT", "END:VCALENDAR")
+
+	var b bytes.Buffer
+	for _, line := range lines {
+		writeICSLine(&b, line)
+	}
+	return b.Bytes()
+}
+
+// writeICSLine writes a content line folded at 75 octets, without splitting
+// UTF-8 sequences
+func writeICSLine(b *bytes.Buffer, line string) {
+	limit := 75
+	for len(line) > limit {
+		i := limit
+		for i > 0 && !utf8.RuneStart(line[i]) {
+			i--
+		}
+		b.WriteString(line[:i] + "\r\n ")
+		line = line[i:]
+		// continuation lines start with a space
+		limit = 74
+	}
+	b.WriteString(line + "\r\n")
+}
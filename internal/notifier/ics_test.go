@@ -0,0 +1,41 @@
+package notifier
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteICSLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{
+			name: "short",
+			line: "SUMMARY:water the plants",
+			want: "SUMMARY:water the plants\r\n",
+		},
+		{
+			name: "folded",
+			line: "DESCRIPTION:" + strings.Repeat("a", 70),
+			want: "DESCRIPTION:" + strings.Repeat("a", 63) + "\r\n " + strings.Repeat("a", 7) + "\r\n",
+		},
+		{
+			name: "multi-byte",
+			line: "SUMMARY:" + strings.Repeat("a", 66) + "é",
+			// é takes octets 75 and 76, it moves to the next line whole
+			want: "SUMMARY:" + strings.Repeat("a", 66) + "\r\n é\r\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			writeICSLine(&b, tt.line)
+			if b.String() != tt.want {
+				t.Errorf("writeICSLine() = %q, want %q", b.String(), tt.want)
+			}
+		})
+	}
+}
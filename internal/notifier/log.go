@@ -0,0 +1,30 @@
+package notifier
+
+import (
+	"context"
+	"io"
+	"log"
+	"time"
+
+	"github.com/elangreza/scheduler/internal"
+)
+
+// Log writes fired schedules to a logger, it is meant for development
+type Log struct {
+	logger *log.Logger
+}
+
+func NewLog(w io.Writer) *Log {
+	return &Log{logger: log.New(w, "notifier: ", log.LstdFlags)}
+}
+
+func (n *Log) Send(ctx context.Context, schedule *internal.Schedule, task internal.Task) error {
+	n.logger.Printf("schedule %d of task %d %q fired, notify at %s: %s",
+		schedule.ID,
+		task.ID,
+		task.Name,
+		schedule.NotifyAt.Format(time.RFC3339),
+		task.Description,
+	)
+	return nil
+}
@@ -0,0 +1,34 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elangreza/scheduler/internal"
+)
+
+func TestLog_Send(t *testing.T) {
+	var buf bytes.Buffer
+	schedule := &internal.Schedule{
+		ID:       7,
+		NotifyAt: time.Date(2025, 7, 20, 10, 0, 0, 0, time.UTC),
+	}
+	task := internal.Task{ID: 3, Name: "standup", Description: "daily standup"}
+
+	if err := NewLog(&buf).Send(context.Background(), schedule, task); err != nil {
+		t.Fatal(err)
+	}
+
+	got := buf.String()
+	if !strings.HasPrefix(got, "notifier: ") {
+		t.Errorf("Send() wrote %q, want the notifier prefix", got)
+	}
+	for _, part := range []string{`schedule 7 of task 3 "standup" fired`, "2025-07-20T10:00:00Z", "daily standup"} {
+		if !strings.Contains(got, part) {
+			t.Errorf("Send() wrote %q, want it to contain %q", got, part)
+		}
+	}
+}
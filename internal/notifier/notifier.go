@@ -0,0 +1,59 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/elangreza/scheduler/internal"
+	"github.com/elangreza/scheduler/internal/mailer"
+)
+
+type (
+	// Notifier delivers a fired Schedule of a Task through one channel. The
+	// Reminder of the schedule is attached to it by the dispatcher, and the
+	// notifier may record the delivery result on the schedule.
+	Notifier interface {
+		Send(ctx context.Context, schedule *internal.Schedule, task internal.Task) error
+	}
+
+	// Composer is a Notifier whose notifications are emails, composed when
+	// the schedule fires and delivered later by the outbox relay
+	Composer interface {
+		Notifier
+		Compose(ctx context.Context, schedule *internal.Schedule, task internal.Task) ([]mailer.Message, error)
+	}
+
+	// Registry holds the notifiers available to reminders, keyed by channel
+	Registry struct {
+		mu        sync.RWMutex
+		notifiers map[string]Notifier
+	}
+)
+
+func NewRegistry() *Registry {
+	return &Registry{
+		notifiers: make(map[string]Notifier),
+	}
+}
+
+// Register makes a notifier available for the channel, replacing any
+// notifier previously registered for it
+func (r *Registry) Register(channel string, notifier Notifier) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.notifiers[channel] = notifier
+}
+
+func (r *Registry) Get(channel string) (Notifier, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	notifier, ok := r.notifiers[channel]
+	if !ok {
+		return nil, fmt.Errorf("no notifier registered for channel %q", channel)
+	}
+
+	return notifier, nil
+}
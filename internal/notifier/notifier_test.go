@@ -0,0 +1,36 @@
+package notifier
+
+import (
+	"testing"
+
+	"github.com/elangreza/scheduler/internal"
+)
+
+func TestRegistry_Get(t *testing.T) {
+	registry := NewRegistry()
+	first := NewLog(nil)
+	second := NewLog(nil)
+	registry.Register(internal.ChannelLog, first)
+
+	got, err := registry.Get(internal.ChannelLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != first {
+		t.Errorf("Get(%q) = %p, want %p", internal.ChannelLog, got, first)
+	}
+
+	// registering a channel again replaces its notifier
+	registry.Register(internal.ChannelLog, second)
+	got, err = registry.Get(internal.ChannelLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != second {
+		t.Errorf("Get(%q) = %p, want %p", internal.ChannelLog, got, second)
+	}
+
+	if _, err := registry.Get(internal.ChannelWebhook); err == nil {
+		t.Errorf("Get(%q) error = nil, want an unknown channel error", internal.ChannelWebhook)
+	}
+}
@@ -0,0 +1,120 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/elangreza/scheduler/internal"
+)
+
+const (
+	// SignatureHeader carries the hex HMAC-SHA256 of the timestamp and the
+	// request body, keyed by the webhook secret of the reminder, as
+	// "sha256=<hex>"
+	SignatureHeader = "X-Scheduler-Signature"
+	// TimestampHeader carries the unix time the request was signed at.
+	// Receivers reject old timestamps so captured requests cannot be
+	// replayed.
+	TimestampHeader = "X-Scheduler-Timestamp"
+)
+
+type (
+	// Webhook posts fired schedules as JSON to the webhook url of the reminder.
+	// Each fire posts once, the dispatcher retries network errors, 429 and 5xx
+	// responses with its retry policy. Other responses fail permanently.
+	Webhook struct {
+		client *http.Client
+		clock  internal.Clock
+	}
+
+	// WebhookPayload is the JSON body posted to webhooks
+	WebhookPayload struct {
+		TaskID      int64     `json:"task_id"`
+		TaskName    string    `json:"task_name"`
+		Description string    `json:"description"`
+		ReminderID  int64     `json:"reminder_id"`
+		ScheduleID  int64     `json:"schedule_id"`
+		NotifyAt    time.Time `json:"notify_at"`
+	}
+)
+
+// NewWebhook creates a webhook notifier. A nil client falls back to one
+// timing out after 10 seconds, a nil clock to the system clock.
+func NewWebhook(client *http.Client, clock internal.Clock) *Webhook {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if clock == nil {
+		clock = internal.SystemClock{}
+	}
+	return &Webhook{client: client, clock: clock}
+}
+
+// Send posts the schedule and records the response status on it. Failures
+// not worth retrying are returned as internal.PermanentError.
+func (n *Webhook) Send(ctx context.Context, schedule *internal.Schedule, task internal.Task) error {
+	if schedule.Reminder == nil || schedule.Reminder.WebhookURL == "" {
+		return internal.PermanentError{Err: fmt.Errorf("schedule %d has no webhook url", schedule.ID)}
+	}
+
+	body, err := json.Marshal(WebhookPayload{
+		TaskID:      task.ID,
+		TaskName:    task.Name,
+		Description: task.Description,
+		ReminderID:  schedule.ReminderID,
+		ScheduleID:  schedule.ID,
+		NotifyAt:    schedule.NotifyAt,
+	})
+	if err != nil {
+		return internal.PermanentError{Err: err}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, schedule.Reminder.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return internal.PermanentError{Err: err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if schedule.Reminder.WebhookSecret != "" {
+		timestamp := strconv.FormatInt(n.clock.Now().Unix(), 10)
+		req.Header.Set(TimestampHeader, timestamp)
+		req.Header.Set(SignatureHeader, Sign(schedule.Reminder.WebhookSecret, timestamp, body))
+	}
+
+	res, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, res.Body)
+
+	schedule.ResponseStatus = res.StatusCode
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return nil
+	}
+
+	err = fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	if res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500 {
+		return err
+	}
+	return internal.PermanentError{Err: err}
+}
+
+// Sign returns the value of SignatureHeader for a body sent at timestamp, the
+// value of TimestampHeader, signed with secret. Receivers verify it by
+// computing the same value and comparing it with hmac.Equal.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
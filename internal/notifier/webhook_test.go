@@ -0,0 +1,146 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/elangreza/scheduler/internal"
+)
+
+func TestWebhook_Send(t *testing.T) {
+	notifyAt := time.Date(2025, 7, 20, 10, 0, 0, 0, time.UTC)
+	now := notifyAt.Add(time.Second)
+	task := internal.Task{ID: 1, Name: "standup", Description: "daily standup"}
+
+	tests := []struct {
+		name          string
+		secret        string
+		status        int
+		wantErr       bool
+		wantPermanent bool
+		wantStatus    int
+	}{
+		{
+			name:       "signed delivery",
+			secret:     "s3cr3t",
+			status:     http.StatusOK,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "unsigned delivery",
+			status:     http.StatusNoContent,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "server error is retryable",
+			secret:     "s3cr3t",
+			status:     http.StatusBadGateway,
+			wantErr:    true,
+			wantStatus: http.StatusBadGateway,
+		},
+		{
+			name:       "too many requests is retryable",
+			status:     http.StatusTooManyRequests,
+			wantErr:    true,
+			wantStatus: http.StatusTooManyRequests,
+		},
+		{
+			name:          "client error is permanent",
+			status:        http.StatusBadRequest,
+			wantErr:       true,
+			wantPermanent: true,
+			wantStatus:    http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				body, _ := io.ReadAll(r.Body)
+
+				signature, timestamp := r.Header.Get(SignatureHeader), r.Header.Get(TimestampHeader)
+				if tt.secret == "" && (signature != "" || timestamp != "") {
+					t.Errorf("unexpected signature %q at %q", signature, timestamp)
+				}
+				if tt.secret != "" {
+					if want := strconv.FormatInt(now.Unix(), 10); timestamp != want {
+						t.Errorf("timestamp = %q, want %q", timestamp, want)
+					}
+					if want := Sign(tt.secret, timestamp, body); signature != want {
+						t.Errorf("signature = %q, want %q", signature, want)
+					}
+				}
+
+				var payload WebhookPayload
+				if err := json.Unmarshal(body, &payload); err != nil {
+					t.Errorf("invalid payload: %v", err)
+				}
+				want := WebhookPayload{TaskID: 1, TaskName: "standup", Description: "daily standup", ReminderID: 2, ScheduleID: 3, NotifyAt: notifyAt}
+				if payload != want {
+					t.Errorf("payload = %+v, want %+v", payload, want)
+				}
+
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			schedule := &internal.Schedule{
+				ID:         3,
+				TaskID:     1,
+				ReminderID: 2,
+				NotifyAt:   notifyAt,
+				Reminder:   &internal.Reminder{ID: 2, WebhookURL: srv.URL, WebhookSecret: tt.secret},
+			}
+
+			err := NewWebhook(srv.Client(), internal.NewFakeClock(now)).Send(context.Background(), schedule, task)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Webhook.Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var permanent internal.PermanentError
+			if errors.As(err, &permanent) != tt.wantPermanent {
+				t.Errorf("Webhook.Send() error = %v, wantPermanent %v", err, tt.wantPermanent)
+			}
+			if calls != 1 {
+				t.Errorf("Webhook.Send() calls = %d, want 1", calls)
+			}
+			if schedule.ResponseStatus != tt.wantStatus {
+				t.Errorf("Schedule.ResponseStatus = %d, want %d", schedule.ResponseStatus, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestWebhook_Send_unreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	schedule := &internal.Schedule{ID: 3, Reminder: &internal.Reminder{ID: 2, WebhookURL: srv.URL}}
+	err := NewWebhook(nil, nil).Send(context.Background(), schedule, internal.Task{ID: 1})
+	var permanent internal.PermanentError
+	if err == nil || errors.As(err, &permanent) {
+		t.Errorf("Webhook.Send() error = %v, want a retryable error", err)
+	}
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"schedule_id":3}`)
+	signature := Sign("s3cr3t", "1752998400", body)
+
+	if Sign("s3cr3t", "1752998401", body) == signature {
+		t.Errorf("Sign() does not depend on the timestamp")
+	}
+	if Sign("s3cr3t", "1752998400", []byte(`{"schedule_id":4}`)) == signature {
+		t.Errorf("Sign() does not depend on the body")
+	}
+	if Sign("other", "1752998400", body) == signature {
+		t.Errorf("Sign() does not depend on the secret")
+	}
+}
@@ -0,0 +1,44 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+)
+
+// OutboxEntry is a notification of a fired schedule waiting in the outbox
+// for the relay to deliver it. Entries are written in the transaction
+// finishing their schedule, so a fired schedule is neither lost nor notified
+// twice by a crash between the two. The relay may still deliver an entry
+// twice when it crashes between the delivery and storing it, every attempt
+// delivers the same Payload so receivers can drop the duplicate.
+type OutboxEntry struct {
+	ID         int64 `json:"id"`
+	ScheduleID int64 `json:"schedule_id"`
+	// DedupeKey identifies the notification, the outbox holds a single
+	// entry per key
+	DedupeKey string `json:"dedupe_key"`
+	// Payload is the encoded notification, e.g., an email
+	Payload []byte `json:"-"`
+	// Status is StatusCreated until the relay delivers the entry,
+	// StatusSending while it does, StatusFailed waiting for a retry, then
+	// StatusSuccess or StatusDead
+	Status        ActionStatus `json:"status"`
+	Attempts      int          `json:"attempts"`
+	LastError     string       `json:"last_error"`
+	NextAttemptAt time.Time    `json:"next_attempt_at"`
+	SentAt        time.Time    `json:"sent_at"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+}
+
+// NewOutboxEntry returns the entry of the index-th notification, from 0, of
+// a schedule, due at now
+func NewOutboxEntry(scheduleID int64, index int, payload []byte, now time.Time) *OutboxEntry {
+	return &OutboxEntry{
+		ScheduleID:    scheduleID,
+		DedupeKey:     fmt.Sprintf("schedule-%d-%d", scheduleID, index),
+		Payload:       payload,
+		Status:        StatusCreated,
+		NextAttemptAt: now,
+	}
+}
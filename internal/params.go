@@ -1,5 +1,7 @@
 package internal
 
+import "time"
+
 // old
 // type CreateTaskParams struct {
 // 	Name, Description string
@@ -12,8 +14,102 @@ package internal
 
 type CreateTaskParams struct {
 	Name, Description string
+	EmailTemplate     string `json:"email_template"`
 }
 
 type UpdateTaskParams struct {
 	Name, Description string
+	EmailTemplate     string `json:"email_template"`
+}
+
+type CreateReminderParams struct {
+	StartTime    string `json:"start_time"`
+	EndTime      string `json:"end_time"`
+	RepeatHourly string `json:"repeat_hourly"`
+	RepeatDaily  []int  `json:"repeat_daily"`
+	Cron         string `json:"cron"`
+	RRule        string `json:"rrule"`
+	Timezone     string `json:"timezone"`
+
+	RepeatMonthly int    `json:"repeat_monthly"`
+	RepeatYearly  string `json:"repeat_yearly"`
+
+	MaxOccurrences int    `json:"max_occurrences"`
+	MisfirePolicy  string `json:"misfire_policy"`
+
+	EscalationPolicyID int64 `json:"escalation_policy_id"`
+
+	Calendar      string `json:"calendar"`
+	HolidayPolicy string `json:"holiday_policy"`
+
+	Recipients    []string `json:"recipients"`
+	Channel       string   `json:"channel"`
+	WebhookURL    string   `json:"webhook_url"`
+	WebhookSecret string   `json:"webhook_secret"`
+}
+
+type UpdateReminderParams struct {
+	StartTime    string `json:"start_time"`
+	EndTime      string `json:"end_time"`
+	RepeatHourly string `json:"repeat_hourly"`
+	RepeatDaily  []int  `json:"repeat_daily"`
+	Cron         string `json:"cron"`
+	RRule        string `json:"rrule"`
+	Timezone     string `json:"timezone"`
+
+	RepeatMonthly int    `json:"repeat_monthly"`
+	RepeatYearly  string `json:"repeat_yearly"`
+
+	MaxOccurrences int    `json:"max_occurrences"`
+	MisfirePolicy  string `json:"misfire_policy"`
+
+	EscalationPolicyID int64 `json:"escalation_policy_id"`
+
+	Calendar      string `json:"calendar"`
+	HolidayPolicy string `json:"holiday_policy"`
+
+	Recipients    []string `json:"recipients"`
+	Channel       string   `json:"channel"`
+	WebhookURL    string   `json:"webhook_url"`
+	WebhookSecret string   `json:"webhook_secret"`
+}
+
+type CreateReminderExceptionParams struct {
+	Kind         string `json:"kind"`
+	Date         string `json:"date"`
+	OccurrenceAt string `json:"occurrence_at"`
+	RunAt        string `json:"run_at"`
+}
+
+// OccurrenceParams selects the run times returned by an occurrence preview.
+// A zero From means now, a zero Until means no upper bound.
+type OccurrenceParams struct {
+	From  time.Time
+	Until time.Time
+	Count int
+}
+
+// SnoozeScheduleParams snoozes a fired schedule for a duration, e.g., "1h",
+// or until a time, exactly one of them must be set
+type SnoozeScheduleParams struct {
+	Duration string `json:"duration"`
+	Until    string `json:"until"`
+}
+
+type CreateEscalationPolicyParams struct {
+	Name  string           `json:"name"`
+	Steps []EscalationStep `json:"steps"`
+}
+
+type UpdateEscalationPolicyParams struct {
+	Name  string           `json:"name"`
+	Steps []EscalationStep `json:"steps"`
+}
+
+// PutQuietHoursParams sets the quiet hours of a recipient
+type PutQuietHoursParams struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Timezone string `json:"timezone"`
+	Policy   string `json:"policy"`
 }
@@ -0,0 +1,260 @@
+package internal
+
+import (
+	"fmt"
+	"net/mail"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// QuietDefer delivers notifications due inside quiet hours at their end
+	QuietDefer = "defer"
+	// QuietDrop never delivers notifications due inside quiet hours
+	QuietDrop = "drop"
+
+	// ScheduleDeferred is a schedule delivering, at the end of their quiet
+	// hours, the notification of a schedule to the recipients it held back.
+	// Its ParentID is the deferred schedule, or the escalated schedule of a
+	// deferred escalation.
+	ScheduleDeferred = "deferred"
+)
+
+type (
+	// QuietHours is a daily window notifications are held back in. The global
+	// quiet hours apply to every recipient without quiet hours of their own,
+	// and to channels without recipients.
+	QuietHours struct {
+		Recipient string    `json:"recipient"` // email address, empty for the global quiet hours
+		Start     string    `json:"start"`     // wall clock the window starts at, e.g., "22:00"
+		End       string    `json:"end"`       // wall clock the window ends at, before Start for windows spanning midnight
+		Timezone  string    `json:"timezone"`  // IANA time zone of the wall clocks, defaults to UTC
+		Policy    string    `json:"policy"`    // QuietDefer (default) or QuietDrop
+		CreatedAt time.Time `json:"created_at"`
+		UpdatedAt time.Time `json:"updated_at"`
+
+		// start and end are parsed Start and End, in minutes after midnight
+		start, end int
+		// location is loaded Timezone
+		location *time.Location
+	}
+
+	// QuietWindows resolves the quiet hours of recipients
+	QuietWindows struct {
+		global     *QuietHours
+		recipients map[string]*QuietHours
+	}
+
+	// QuietDelivery is how quiet hours hold back the delivery of a schedule
+	QuietDelivery struct {
+		// Send reports whether the schedule is delivered now, to Recipients
+		// for an email schedule
+		Send       bool
+		Recipients []string
+		// Deferred deliver the notification to held back recipients at the
+		// end of their quiet hours, Dropped are never notified
+		Deferred []*Schedule
+		Dropped  []string
+	}
+)
+
+func NewQuietHours(recipient, start, end, timezone, policy string) (*QuietHours, error) {
+	quietHours := &QuietHours{
+		Recipient: recipient,
+		Start:     start,
+		End:       end,
+		Timezone:  timezone,
+		Policy:    policy,
+	}
+
+	if err := quietHours.Validate(); err != nil {
+		return nil, err
+	}
+
+	return quietHours, nil
+}
+
+// Validate checks the quiet hours and parses their window. Quiet hours loaded
+// from storage must be validated before they are used. The recipient is
+// stored as its lower case address, so it matches however reminders spell it.
+func (q *QuietHours) Validate() error {
+	if q.Recipient != "" {
+		address, err := RecipientAddress(q.Recipient)
+		if err != nil {
+			return fmt.Errorf("invalid recipient %q: %v", q.Recipient, err)
+		}
+		q.Recipient = address
+	}
+
+	var err error
+	q.start, err = parseWallClock(q.Start)
+	if err != nil {
+		return fmt.Errorf("invalid quiet hours start: %v", err)
+	}
+
+	q.end, err = parseWallClock(q.End)
+	if err != nil {
+		return fmt.Errorf("invalid quiet hours end: %v", err)
+	}
+
+	if q.start == q.end {
+		return fmt.Errorf("quiet hours start and end cannot be equal")
+	}
+
+	q.location, err = time.LoadLocation(q.Timezone)
+	if err != nil {
+		return fmt.Errorf("invalid timezone %q: %v", q.Timezone, err)
+	}
+
+	switch q.Policy {
+	case "", QuietDefer, QuietDrop:
+	default:
+		return fmt.Errorf("invalid quiet hours policy %q, must be %s or %s", q.Policy, QuietDefer, QuietDrop)
+	}
+
+	return nil
+}
+
+// Until returns the end of the quiet hours t falls in, or the zero time when t
+// is outside of them. Nil quiet hours never hold a notification back.
+func (q *QuietHours) Until(t time.Time) time.Time {
+	if q == nil {
+		return time.Time{}
+	}
+
+	local := t.In(q.location)
+	minute := local.Hour()*60 + local.Minute()
+	day := local
+	if q.start < q.end {
+		if minute < q.start || minute >= q.end {
+			return time.Time{}
+		}
+	} else {
+		if minute >= q.end && minute < q.start {
+			return time.Time{}
+		}
+		if minute >= q.start {
+			// the window ends the next day
+			day = local.AddDate(0, 0, 1)
+		}
+	}
+
+	end := time.Date(day.Year(), day.Month(), day.Day(), q.end/60, q.end%60, 0, 0, time.UTC)
+	return wallClock(end, q.location)
+}
+
+// Location returns the time zone of the wall clocks of the quiet hours, the
+// time zone their recipient reads times in
+func (q *QuietHours) Location() *time.Location {
+	return q.location
+}
+
+// parseWallClock parses a "15:04" wall clock into minutes after midnight
+func parseWallClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("must be HH:MM: %v", err)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// RecipientAddress returns the lower case address of an email recipient, e.g.,
+// "ops@example.com" for "Ops <OPS@example.com>"
+func RecipientAddress(recipient string) (string, error) {
+	address, err := mail.ParseAddress(recipient)
+	if err != nil {
+		return "", err
+	}
+	return strings.ToLower(address.Address), nil
+}
+
+// NewQuietWindows returns the quiet windows of the global quiet hours, nil for
+// none, and of the recipients with quiet hours of their own
+func NewQuietWindows(global *QuietHours, recipients []QuietHours) QuietWindows {
+	windows := QuietWindows{
+		global:     global,
+		recipients: make(map[string]*QuietHours, len(recipients)),
+	}
+	for i := range recipients {
+		windows.recipients[recipients[i].Recipient] = &recipients[i]
+	}
+	return windows
+}
+
+// For returns the quiet hours of recipient, its own or the global ones, nil
+// when none apply
+func (w QuietWindows) For(recipient string) *QuietHours {
+	if address, err := RecipientAddress(recipient); err == nil {
+		if quietHours, ok := w.recipients[address]; ok {
+			return quietHours
+		}
+	}
+	return w.global
+}
+
+// Quiet applies the quiet windows to the delivery of the schedule at now.
+// reminder is the reminder the schedule notifies, escalated for an
+// escalation. Email recipients are held back by their own quiet hours, other
+// channels by the global ones.
+func (s *Schedule) Quiet(reminder *Reminder, windows QuietWindows, now time.Time) QuietDelivery {
+	if reminder.DeliveryChannel() != ChannelEmail {
+		until := windows.global.Until(now)
+		switch {
+		case until.IsZero():
+			return QuietDelivery{Send: true}
+		case windows.global.Policy == QuietDrop:
+			return QuietDelivery{}
+		default:
+			return QuietDelivery{Deferred: []*Schedule{s.deferred(until)}}
+		}
+	}
+
+	recipients := reminder.Recipients
+	if len(s.Recipients) > 0 {
+		recipients = s.Recipients
+	}
+
+	var delivery QuietDelivery
+	for _, recipient := range recipients {
+		quietHours := windows.For(recipient)
+		until := quietHours.Until(now)
+		switch {
+		case until.IsZero():
+			delivery.Recipients = append(delivery.Recipients, recipient)
+		case quietHours.Policy == QuietDrop:
+			delivery.Dropped = append(delivery.Dropped, recipient)
+		default:
+			i := slices.IndexFunc(delivery.Deferred, func(deferred *Schedule) bool {
+				return deferred.NotifyAt.Equal(until)
+			})
+			if i < 0 {
+				delivery.Deferred = append(delivery.Deferred, s.deferred(until))
+				i = len(delivery.Deferred) - 1
+			}
+			delivery.Deferred[i].Recipients = append(delivery.Deferred[i].Recipients, recipient)
+		}
+	}
+
+	// a schedule without recipients is left to fail in its notifier
+	delivery.Send = len(delivery.Recipients) > 0 || len(recipients) == 0
+	return delivery
+}
+
+// Held reports whether quiet hours held back any recipient of the delivery
+func (d QuietDelivery) Held() bool {
+	return len(d.Deferred) > 0 || len(d.Dropped) > 0 || !d.Send
+}
+
+// deferred returns the schedule delivering the schedule at until
+func (s *Schedule) deferred(until time.Time) *Schedule {
+	deferred := NewSchedule(s.TaskID, s.ReminderID, until)
+	deferred.Kind = ScheduleDeferred
+	deferred.ParentID = s.ID
+	if s.Kind == ScheduleEscalation || s.Kind == ScheduleDeferred {
+		deferred.ParentID = s.ParentID
+	}
+	deferred.Step = s.Step
+	deferred.OccurrenceAt = s.OccurrenceAt
+	return deferred
+}
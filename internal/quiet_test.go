@@ -0,0 +1,239 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewQuietHours(t *testing.T) {
+	tests := []struct {
+		name          string
+		recipient     string
+		start, end    string
+		timezone      string
+		policy        string
+		wantRecipient string
+		wantErr       bool
+	}{
+		{name: "global", start: "22:00", end: "07:00"},
+		{name: "recipient address is normalized", recipient: "Ops <OPS@example.com>", start: "22:00", end: "07:00", timezone: "Europe/Berlin", policy: QuietDrop, wantRecipient: "ops@example.com"},
+		{name: "same day window", start: "12:00", end: "13:30", policy: QuietDefer},
+		{name: "invalid recipient", recipient: "ops", start: "22:00", end: "07:00", wantErr: true},
+		{name: "invalid start", start: "10pm", end: "07:00", wantErr: true},
+		{name: "invalid end", start: "22:00", end: "24:00", wantErr: true},
+		{name: "empty window", start: "22:00", end: "22:00", wantErr: true},
+		{name: "invalid timezone", start: "22:00", end: "07:00", timezone: "Mars/Olympus", wantErr: true},
+		{name: "invalid policy", start: "22:00", end: "07:00", policy: "snooze", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quietHours, err := NewQuietHours(tt.recipient, tt.start, tt.end, tt.timezone, tt.policy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewQuietHours() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && quietHours.Recipient != tt.wantRecipient {
+				t.Errorf("NewQuietHours() recipient = %q, want %q", quietHours.Recipient, tt.wantRecipient)
+			}
+		})
+	}
+}
+
+func TestQuietHours_Until(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+
+	tests := []struct {
+		name       string
+		start, end string
+		timezone   string
+		at         time.Time
+		want       time.Time
+	}{
+		{
+			name:  "before a window spanning midnight",
+			start: "22:00", end: "07:00",
+			at:   time.Date(2025, 7, 20, 21, 59, 0, 0, time.UTC),
+			want: time.Time{},
+		},
+		{
+			name:  "window spanning midnight, before midnight",
+			start: "22:00", end: "07:00",
+			at:   time.Date(2025, 7, 20, 22, 0, 0, 0, time.UTC),
+			want: time.Date(2025, 7, 21, 7, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "window spanning midnight, after midnight",
+			start: "22:00", end: "07:00",
+			at:   time.Date(2025, 7, 21, 3, 0, 0, 0, time.UTC),
+			want: time.Date(2025, 7, 21, 7, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "end of the window is outside of it",
+			start: "22:00", end: "07:00",
+			at:   time.Date(2025, 7, 21, 7, 0, 0, 0, time.UTC),
+			want: time.Time{},
+		},
+		{
+			name:  "same day window",
+			start: "12:00", end: "13:30",
+			at:   time.Date(2025, 7, 21, 12, 45, 0, 0, time.UTC),
+			want: time.Date(2025, 7, 21, 13, 30, 0, 0, time.UTC),
+		},
+		{
+			name:  "after a same day window",
+			start: "12:00", end: "13:30",
+			at:   time.Date(2025, 7, 21, 14, 0, 0, 0, time.UTC),
+			want: time.Time{},
+		},
+		{
+			name:  "in the time zone of the window",
+			start: "22:00", end: "07:00", timezone: "Europe/Berlin",
+			at:   time.Date(2025, 7, 20, 20, 30, 0, 0, time.UTC),
+			want: time.Date(2025, 7, 21, 7, 0, 0, 0, berlin),
+		},
+		{
+			name:  "window ending in a DST gap",
+			start: "01:00", end: "02:30", timezone: "Europe/Berlin",
+			at:   time.Date(2025, 3, 30, 1, 30, 0, 0, berlin),
+			want: time.Date(2025, 3, 30, 3, 30, 0, 0, berlin),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quietHours, err := NewQuietHours("", tt.start, tt.end, tt.timezone, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := quietHours.Until(tt.at); !got.Equal(tt.want) {
+				t.Errorf("QuietHours.Until() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	var none *QuietHours
+	if got := none.Until(time.Now()); !got.IsZero() {
+		t.Errorf("nil QuietHours.Until() = %v, want the zero time", got)
+	}
+}
+
+func TestSchedule_Quiet(t *testing.T) {
+	now := time.Date(2025, 7, 20, 23, 0, 0, 0, time.UTC)
+	occurrence := now.Add(-time.Minute)
+
+	quietHours := func(recipient, start, end, policy string) *QuietHours {
+		q, err := NewQuietHours(recipient, start, end, "", policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return q
+	}
+	global := quietHours("", "22:00", "07:00", QuietDefer)
+	recipients := []QuietHours{
+		*quietHours("owl@example.com", "02:00", "04:00", QuietDrop),
+		*quietHours("lark@example.com", "20:00", "05:00", QuietDefer),
+		*quietHours("busy@example.com", "21:00", "23:30", QuietDrop),
+	}
+
+	deferred := func(until time.Time, parentID int64, step int, recipients ...string) *Schedule {
+		return &Schedule{
+			TaskID:       1,
+			ReminderID:   2,
+			Status:       StatusCreated,
+			NotifyAt:     until,
+			OccurrenceAt: occurrence,
+			Kind:         ScheduleDeferred,
+			ParentID:     parentID,
+			Step:         step,
+			Recipients:   recipients,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		schedule Schedule
+		reminder Reminder
+		global   *QuietHours
+		want     QuietDelivery
+	}{
+		{
+			name:     "no quiet hours",
+			schedule: Schedule{ID: 3, TaskID: 1, ReminderID: 2, Kind: ScheduleOccurrence},
+			reminder: Reminder{Recipients: []string{"ops@example.com"}},
+			want:     QuietDelivery{Send: true, Recipients: []string{"ops@example.com"}},
+		},
+		{
+			name:     "recipients split by their quiet hours",
+			schedule: Schedule{ID: 3, TaskID: 1, ReminderID: 2, Kind: ScheduleOccurrence, OccurrenceAt: occurrence},
+			reminder: Reminder{Recipients: []string{"ops@example.com", "Owl <owl@example.com>", "lark@example.com", "busy@example.com", "night@example.com"}},
+			global:   global,
+			want: QuietDelivery{
+				Send:       true,
+				Recipients: []string{"Owl <owl@example.com>"},
+				Deferred: []*Schedule{
+					deferred(time.Date(2025, 7, 21, 7, 0, 0, 0, time.UTC), 3, 0, "ops@example.com", "night@example.com"),
+					deferred(time.Date(2025, 7, 21, 5, 0, 0, 0, time.UTC), 3, 0, "lark@example.com"),
+				},
+				Dropped: []string{"busy@example.com"},
+			},
+		},
+		{
+			name:     "every recipient held back",
+			schedule: Schedule{ID: 3, TaskID: 1, ReminderID: 2, Kind: ScheduleOccurrence, OccurrenceAt: occurrence},
+			reminder: Reminder{Recipients: []string{"ops@example.com", "busy@example.com"}},
+			global:   global,
+			want: QuietDelivery{
+				Deferred: []*Schedule{deferred(time.Date(2025, 7, 21, 7, 0, 0, 0, time.UTC), 3, 0, "ops@example.com")},
+				Dropped:  []string{"busy@example.com"},
+			},
+		},
+		{
+			name:     "recipients of the schedule replace the ones of the reminder",
+			schedule: Schedule{ID: 3, TaskID: 1, ReminderID: 2, Kind: ScheduleDeferred, ParentID: 1, Recipients: []string{"owl@example.com"}},
+			reminder: Reminder{Recipients: []string{"ops@example.com"}},
+			global:   global,
+			want:     QuietDelivery{Send: true, Recipients: []string{"owl@example.com"}},
+		},
+		{
+			name:     "escalation is deferred with its step and escalated schedule",
+			schedule: Schedule{ID: 4, TaskID: 1, ReminderID: 2, Kind: ScheduleEscalation, ParentID: 3, Step: 2, OccurrenceAt: occurrence},
+			reminder: Reminder{Recipients: []string{"lead@example.com"}},
+			global:   global,
+			want: QuietDelivery{
+				Deferred: []*Schedule{deferred(time.Date(2025, 7, 21, 7, 0, 0, 0, time.UTC), 3, 2, "lead@example.com")},
+			},
+		},
+		{
+			name:     "other channels are deferred by the global quiet hours",
+			schedule: Schedule{ID: 3, TaskID: 1, ReminderID: 2, Kind: ScheduleOccurrence, OccurrenceAt: occurrence},
+			reminder: Reminder{Channel: ChannelLog},
+			global:   global,
+			want: QuietDelivery{
+				Deferred: []*Schedule{deferred(time.Date(2025, 7, 21, 7, 0, 0, 0, time.UTC), 3, 0)},
+			},
+		},
+		{
+			name:     "other channels are dropped by the global quiet hours",
+			schedule: Schedule{ID: 3, TaskID: 1, ReminderID: 2, Kind: ScheduleOccurrence},
+			reminder: Reminder{Channel: ChannelLog},
+			global:   quietHours("", "22:00", "07:00", QuietDrop),
+			want:     QuietDelivery{},
+		},
+		{
+			name:     "other channels ignore the quiet hours of recipients",
+			schedule: Schedule{ID: 3, TaskID: 1, ReminderID: 2, Kind: ScheduleOccurrence},
+			reminder: Reminder{Channel: ChannelLog, Recipients: []string{"busy@example.com"}},
+			want:     QuietDelivery{Send: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.schedule.Quiet(&tt.reminder, NewQuietWindows(tt.global, recipients), now)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Schedule.Quiet() = %+v, want %+v", got, tt.want)
+				for i := range got.Deferred {
+					t.Logf("deferred %d = %+v", i, *got.Deferred[i])
+				}
+			}
+		})
+	}
+}
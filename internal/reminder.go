@@ -2,10 +2,25 @@ package internal
 
 import (
 	"fmt"
+	"net/mail"
+	"net/url"
 	"slices"
 	"time"
 )
 
+const (
+	// maxOccurrenceSteps bounds the run times walked by Occurrences
+	maxOccurrenceSteps = 100000
+	// maxSkippedRuns bounds the consecutive runs dropped by skip exceptions
+	maxSkippedRuns = 1000
+)
+
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+	ChannelLog     = "log"
+)
+
 type (
 	Reminder struct {
 		ID           int64     `json:"id"`
@@ -14,20 +29,170 @@ type (
 		EndTime      time.Time `json:"end_time"`      // optional, can be nil if the Reminder is ongoing
 		RepeatHourly string    `json:"repeat_hourly"` // e.g., "1h", "30m", etc.
 		RepeatDaily  []int     `json:"repeat_daily"`  // days of the week, e.g., [1, 2, 3] for Mon, Tue, Wed
+		Cron         string    `json:"cron"`          // cron expression, e.g., "30 8 * * 1-5" or "@daily", cannot be combined with RepeatHourly or RepeatDaily
+		RRule        string    `json:"rrule"`         // RFC 5545 recurrence rule, e.g., "FREQ=MONTHLY;BYDAY=-1FR", optionally followed by EXDATE lines
+		Timezone     string    `json:"timezone"`      // IANA time zone the Reminder repeats in, e.g., "Europe/Berlin", defaults to the offset of StartTime
+		Recipients   []string  `json:"recipients"`    // email addresses notified when the Reminder fires
+		Channel      string    `json:"channel"`       // delivery channel of the Reminder, e.g., "email", "webhook" or "log"
+		WebhookURL   string    `json:"webhook_url"`   // required when Channel is "webhook"
+		// RepeatMonthly is the day of the month the Reminder repeats on, e.g.,
+		// 1 for the 1st, RepeatYearly the month and day it repeats on every
+		// year, e.g., "12-25". Days past the end of a month run on its last
+		// day. Both repeat at the wall clock of StartTime.
+		RepeatMonthly int    `json:"repeat_monthly"`
+		RepeatYearly  string `json:"repeat_yearly"`
+		// WebhookSecret signs webhook bodies with HMAC-SHA256, it is never
+		// returned to API callers
+		WebhookSecret string `json:"-"`
+		// Calendar names the holiday calendar of the Reminder, HolidayPolicy
+		// decides what happens to runs outside its business days: "skip"
+		// (default), "previous_business_day" or "next_business_day"
+		Calendar      string `json:"calendar"`
+		HolidayPolicy string `json:"holiday_policy"`
+		// Exceptions skip or move single runs of the Reminder, they are
+		// stored apart from the Reminder and attached when it is loaded
+		Exceptions []ReminderException `json:"exceptions"`
+		// MaxOccurrences stops the Reminder once it fired that many times, zero
+		// never stops it. Fired counts the schedules of the Reminder that fired
+		// or are retried, it is loaded from storage.
+		MaxOccurrences int `json:"max_occurrences"`
+		Fired          int `json:"fired"`
+		// MisfirePolicy decides what happens to runs missed while the
+		// dispatcher was down or lagging: "fire_all" (default), "fire_once" or
+		// "skip"
+		MisfirePolicy string `json:"misfire_policy"`
+		// EscalationPolicyID is the escalation policy notifying more
+		// recipients while a fired schedule of the Reminder is not
+		// acknowledged, zero for none. Escalation is the policy, attached when
+		// the Reminder is loaded.
+		EscalationPolicyID int64             `json:"escalation_policy_id"`
+		Escalation         *EscalationPolicy `json:"-"`
 
 		// isRoutine indicates if the Reminder is a routine Reminder
-		isRoutine bool `json:"is_routine"`
+		isRoutine bool
 		// repeatInterval is parsed repeatHourly in time.Duration format
-		repeatInterval time.Duration `json:"repeat_duration"`
+		repeatInterval time.Duration
 		// nextRunAt indicates the next scheduled run time for the Reminder
-		nextRunAt time.Time `json:"next_run_at"`
+		nextRunAt time.Time
+		// recurrence is parsed RepeatMonthly, RepeatYearly, Cron or RRule
+		recurrence recurrence
+		// location is loaded Timezone, nil when Timezone is empty
+		location *time.Location
+		// calendar is the holiday calendar named by Calendar, see SetCalendar
+		calendar *Calendar
 
 		CreatedAt time.Time `json:"created_at"`
 		UpdatedAt time.Time `json:"updated_at"`
 	}
+
+	// recurrence computes the run times of monthly, yearly, cron and rrule
+	// reminders
+	recurrence interface {
+		// next returns the first run time strictly after t, or the zero time
+		next(t time.Time) time.Time
+	}
+
+	// ReminderOption sets an optional field of a Reminder before it is validated
+	ReminderOption func(*Reminder)
 )
 
-func NewReminder(taskID int64, startTime, endTime, repeatHourly string, repeatDaily []int) (*Reminder, error) {
+// WithRepeatMonthly sets the day of the month the Reminder repeats on
+func WithRepeatMonthly(day int) ReminderOption {
+	return func(r *Reminder) {
+		r.RepeatMonthly = day
+	}
+}
+
+// WithRepeatYearly sets the month and day, e.g., "12-25", the Reminder
+// repeats on every year
+func WithRepeatYearly(date string) ReminderOption {
+	return func(r *Reminder) {
+		r.RepeatYearly = date
+	}
+}
+
+// WithCron sets the cron expression the Reminder repeats on
+func WithCron(cron string) ReminderOption {
+	return func(r *Reminder) {
+		r.Cron = cron
+	}
+}
+
+// WithRRule sets the RFC 5545 recurrence rule the Reminder repeats on
+func WithRRule(rrule string) ReminderOption {
+	return func(r *Reminder) {
+		r.RRule = rrule
+	}
+}
+
+// WithTimezone sets the IANA time zone the Reminder repeats in
+func WithTimezone(timezone string) ReminderOption {
+	return func(r *Reminder) {
+		r.Timezone = timezone
+	}
+}
+
+// WithCalendar sets the holiday calendar of the Reminder and the policy
+// applied to runs outside its business days
+func WithCalendar(calendar, policy string) ReminderOption {
+	return func(r *Reminder) {
+		r.Calendar = calendar
+		r.HolidayPolicy = policy
+	}
+}
+
+// WithMaxOccurrences sets the number of times the Reminder fires before it
+// stops, zero never stops it
+func WithMaxOccurrences(max int) ReminderOption {
+	return func(r *Reminder) {
+		r.MaxOccurrences = max
+	}
+}
+
+// WithMisfirePolicy sets the policy applied to missed runs of the Reminder
+func WithMisfirePolicy(policy string) ReminderOption {
+	return func(r *Reminder) {
+		r.MisfirePolicy = policy
+	}
+}
+
+// WithEscalationPolicy sets the escalation policy of the Reminder
+func WithEscalationPolicy(id int64) ReminderOption {
+	return func(r *Reminder) {
+		r.EscalationPolicyID = id
+	}
+}
+
+// WithChannel sets the delivery channel of the Reminder. An empty channel
+// defaults to email.
+func WithChannel(channel string) ReminderOption {
+	return func(r *Reminder) {
+		r.Channel = channel
+	}
+}
+
+// WithWebhookURL sets the URL called when a webhook Reminder fires
+func WithWebhookURL(webhookURL string) ReminderOption {
+	return func(r *Reminder) {
+		r.WebhookURL = webhookURL
+	}
+}
+
+// WithWebhookSecret sets the key used to sign the webhook body of the Reminder
+func WithWebhookSecret(secret string) ReminderOption {
+	return func(r *Reminder) {
+		r.WebhookSecret = secret
+	}
+}
+
+// WithRecipients sets the email addresses notified when the Reminder fires
+func WithRecipients(recipients ...string) ReminderOption {
+	return func(r *Reminder) {
+		r.Recipients = recipients
+	}
+}
+
+func NewReminder(taskID int64, startTime, endTime, repeatHourly string, repeatDaily []int, opts ...ReminderOption) (*Reminder, error) {
 
 	Reminder := &Reminder{
 		TaskID:       taskID,
@@ -35,6 +200,10 @@ func NewReminder(taskID int64, startTime, endTime, repeatHourly string, repeatDa
 		RepeatDaily:  repeatDaily,
 	}
 
+	for _, opt := range opts {
+		opt(Reminder)
+	}
+
 	var err error
 	if startTime == "" {
 		return nil, fmt.Errorf("start time cannot be empty")
@@ -57,14 +226,46 @@ func NewReminder(taskID int64, startTime, endTime, repeatHourly string, repeatDa
 		return nil, err
 	}
 
+	// searching the rule is left out of Validate, which runs on every stored
+	// reminder loaded
+	if Reminder.RRule != "" && Reminder.recurrence.next(Reminder.StartTime.Add(-time.Nanosecond)).IsZero() {
+		return nil, fmt.Errorf("invalid rrule format: the rule has no occurrence")
+	}
+
+	// checked on create and update only, stored reminders without recipients
+	// still load and their schedules are acknowledged
+	if Reminder.DeliveryChannel() == ChannelEmail && len(Reminder.Recipients) == 0 {
+		return nil, fmt.Errorf("email reminder requires at least one recipient")
+	}
+
 	return Reminder, nil
 }
 
+// Validate checks the Reminder and derives its unexported recurrence state.
+// Reminders loaded from storage must be validated before calling GetNextRunAt.
+func (s *Reminder) Validate() error {
+	return s.isValid()
+}
+
 func (s *Reminder) isValid() error {
 	if !s.EndTime.IsZero() && s.StartTime.After(s.EndTime) {
 		return fmt.Errorf("Reminder start time cannot be after end time")
 	}
 
+	s.location = nil
+	if s.Timezone != "" {
+		// Local depends on the host running the dispatcher
+		if s.Timezone == "Local" {
+			return fmt.Errorf("invalid timezone %q, must be an IANA time zone", s.Timezone)
+		}
+
+		var err error
+		s.location, err = time.LoadLocation(s.Timezone)
+		if err != nil {
+			return fmt.Errorf("invalid timezone %q, must be an IANA time zone", s.Timezone)
+		}
+	}
+
 	if s.RepeatHourly != "" {
 		var err error
 		s.repeatInterval, err = time.ParseDuration(s.RepeatHourly)
@@ -95,14 +296,198 @@ func (s *Reminder) isValid() error {
 		s.isRoutine = true
 	}
 
+	s.recurrence = nil
+	if s.RepeatMonthly != 0 {
+		if s.RepeatHourly != "" || len(s.RepeatDaily) > 0 {
+			return fmt.Errorf("repeat monthly cannot be combined with repeat hourly or repeat daily")
+		}
+
+		if s.RepeatMonthly < 1 || s.RepeatMonthly > 31 {
+			return fmt.Errorf("invalid repeat monthly value: %d, must be between 1 and 31", s.RepeatMonthly)
+		}
+
+		s.recurrence = newDateRepeat(0, s.RepeatMonthly, s.StartTime.In(s.Location()))
+		s.isRoutine = true
+	}
+
+	if s.RepeatYearly != "" {
+		if s.RepeatHourly != "" || len(s.RepeatDaily) > 0 || s.RepeatMonthly != 0 {
+			return fmt.Errorf("repeat yearly cannot be combined with repeat hourly, repeat daily or repeat monthly")
+		}
+
+		month, day, err := parseRepeatYearly(s.RepeatYearly)
+		if err != nil {
+			return fmt.Errorf("invalid repeat yearly format: %v", err)
+		}
+
+		s.recurrence = newDateRepeat(month, day, s.StartTime.In(s.Location()))
+		s.isRoutine = true
+	}
+
+	if s.Cron != "" {
+		if s.RepeatHourly != "" || len(s.RepeatDaily) > 0 || s.RepeatMonthly != 0 || s.RepeatYearly != "" {
+			return fmt.Errorf("cron cannot be combined with repeat hourly, repeat daily, repeat monthly or repeat yearly")
+		}
+
+		cron, err := parseCron(s.Cron)
+		if err != nil {
+			return fmt.Errorf("invalid cron format: %v", err)
+		}
+
+		s.recurrence = cron
+		s.isRoutine = true
+	}
+
+	if s.RRule != "" {
+		if s.RepeatHourly != "" || len(s.RepeatDaily) > 0 || s.RepeatMonthly != 0 || s.RepeatYearly != "" || s.Cron != "" {
+			return fmt.Errorf("rrule cannot be combined with repeat hourly, repeat daily, repeat monthly, repeat yearly or cron")
+		}
+
+		rule, err := parseRRule(s.RRule, s.StartTime.In(s.Location()))
+		if err != nil {
+			return fmt.Errorf("invalid rrule format: %v", err)
+		}
+
+		s.recurrence = rule
+		s.isRoutine = true
+	}
+
+	if s.MaxOccurrences < 0 {
+		return fmt.Errorf("max occurrences cannot be less than 0")
+	}
+
+	if s.EscalationPolicyID < 0 {
+		return fmt.Errorf("invalid escalation policy id %d", s.EscalationPolicyID)
+	}
+
+	switch s.MisfirePolicy {
+	case "", MisfireFireAll, MisfireFireOnce, MisfireSkip:
+	default:
+		return fmt.Errorf("invalid misfire policy %q, must be one of %s, %s or %s", s.MisfirePolicy, MisfireFireAll, MisfireFireOnce, MisfireSkip)
+	}
+
+	switch s.HolidayPolicy {
+	case "", HolidaySkip, HolidayPreviousBusinessDay, HolidayNextBusinessDay:
+	default:
+		return fmt.Errorf("invalid holiday policy %q, must be one of %s, %s or %s", s.HolidayPolicy, HolidaySkip, HolidayPreviousBusinessDay, HolidayNextBusinessDay)
+	}
+
+	if s.HolidayPolicy != "" && s.Calendar == "" {
+		return fmt.Errorf("holiday policy requires a calendar")
+	}
+
+	for _, recipient := range s.Recipients {
+		if _, err := mail.ParseAddress(recipient); err != nil {
+			return fmt.Errorf("invalid recipient %q: %v", recipient, err)
+		}
+	}
+
+	switch s.Channel {
+	case "", ChannelEmail, ChannelLog:
+	case ChannelWebhook:
+		u, err := url.Parse(s.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid webhook url %q, must be an absolute http or https url", s.WebhookURL)
+		}
+	default:
+		return fmt.Errorf("invalid channel %q, must be one of %s, %s or %s", s.Channel, ChannelEmail, ChannelWebhook, ChannelLog)
+	}
+
 	return nil
 }
 
+// DeliveryChannel returns the channel used to notify the Reminder, email when
+// no channel is set
+func (s *Reminder) DeliveryChannel() string {
+	if s.Channel == "" {
+		return ChannelEmail
+	}
+	return s.Channel
+}
+
+// Location returns the location the Reminder repeats in: its time zone, or
+// the location of StartTime when no time zone is set. Daily, cron and rrule
+// run times are wall clock times in this location.
+func (s *Reminder) Location() *time.Location {
+	if s.location != nil {
+		return s.location
+	}
+	return s.StartTime.Location()
+}
+
+// FirstRunAt returns the first occurrence of the Reminder. It is the start
+// time, except for monthly, yearly, cron and rrule reminders where it is the
+// first time matching the recurrence at or after the start time. Occurrences
+// on skipped dates are passed over. Occurrences are the run times computed by the
+// recurrence, see RunAt for the time an occurrence fires at.
+func (s *Reminder) FirstRunAt() time.Time {
+	if s.exhausted() {
+		return time.Time{}
+	}
+	return s.firstRun()
+}
+
+// GetNextRunAt returns the occurrence of the Reminder following lastRun, or the
+// zero time when there is none. Occurrences on skipped dates are passed over.
+// A Reminder that fired MaxOccurrences times has no next occurrence.
 func (s *Reminder) GetNextRunAt(lastRun time.Time) time.Time {
+	if s.exhausted() {
+		return time.Time{}
+	}
+	return s.nextRun(lastRun)
+}
+
+// exhausted reports whether the Reminder fired MaxOccurrences times
+func (s *Reminder) exhausted() bool {
+	return s.MaxOccurrences > 0 && s.Fired >= s.MaxOccurrences
+}
+
+// firstRun returns the first occurrence of the Reminder not on a skipped date
+func (s *Reminder) firstRun() time.Time {
+	first := s.firstOccurrence()
+	if !first.IsZero() && s.skipped(first) {
+		return s.nextRun(first)
+	}
+	return first
+}
+
+// nextRun returns the occurrence following lastRun not on a skipped date
+func (s *Reminder) nextRun(lastRun time.Time) time.Time {
+	next := s.nextOccurrence(lastRun)
+	for i := 0; !next.IsZero() && s.skipped(next); i++ {
+		if i == maxSkippedRuns {
+			return time.Time{}
+		}
+		next = s.nextOccurrence(next)
+	}
+	return next
+}
+
+func (s *Reminder) firstOccurrence() time.Time {
+	if s.recurrence == nil {
+		return s.StartTime
+	}
+
+	// next returns occurrences strictly after its argument, the start time is
+	// the first occurrence when it matches the recurrence
+	first := s.recurrence.next(s.StartTime.Add(-time.Nanosecond).In(s.Location()))
+
+	if !s.EndTime.IsZero() && first.After(s.EndTime) {
+		return time.Time{}
+	}
+
+	return first
+}
+
+func (s *Reminder) nextOccurrence(lastRun time.Time) time.Time {
 	if !s.isRoutine {
 		return time.Time{}
 	}
 
+	if s.recurrence != nil {
+		return s.nextRecurrenceRunAt(lastRun)
+	}
+
 	s.nextRunAt = lastRun
 	if s.nextRunAt.IsZero() {
 		s.nextRunAt = s.StartTime
@@ -111,18 +496,23 @@ func (s *Reminder) GetNextRunAt(lastRun time.Time) time.Time {
 	s.nextRunAt = s.nextRunAt.Add(s.repeatInterval)
 
 	// nextRUnAt := s.nextRunAt.Add(s.repeatInterval)
-	if !s.EndTime.IsZero() && s.nextRunAt.After(s.EndTime) {
+	// a reminder without repeatHourly only repeats on the days of repeatDaily
+	if s.repeatInterval == 0 || (!s.EndTime.IsZero() && s.nextRunAt.After(s.EndTime)) {
 		if len(s.RepeatDaily) > 0 {
-			nextDay := generateRunTimeSequence(s.nextRunAt, s.RepeatDaily)
-			return time.Date(
+			// days repeat at the wall clock of the start time, not after a
+			// fixed duration, so they don't drift across DST transitions
+			loc := s.Location()
+			start := s.StartTime.In(loc)
+			nextDay := generateRunTimeSequence(s.nextRunAt.In(loc), s.RepeatDaily)
+			return wallClock(time.Date(
 				nextDay.Year(),
 				nextDay.Month(),
 				nextDay.Day(),
-				s.StartTime.Hour(),
-				s.StartTime.Minute(),
-				s.StartTime.Second(),
-				s.StartTime.Nanosecond(),
-				nextDay.Location())
+				start.Hour(),
+				start.Minute(),
+				start.Second(),
+				start.Nanosecond(),
+				time.UTC), loc)
 		}
 
 		return time.Time{}
@@ -131,6 +521,121 @@ func (s *Reminder) GetNextRunAt(lastRun time.Time) time.Time {
 	return s.nextRunAt
 }
 
+func (s *Reminder) nextRecurrenceRunAt(lastRun time.Time) time.Time {
+	s.nextRunAt = lastRun
+	if s.nextRunAt.IsZero() {
+		s.nextRunAt = s.StartTime
+	}
+
+	s.nextRunAt = s.recurrence.next(s.nextRunAt.In(s.Location()))
+	if !s.EndTime.IsZero() && s.nextRunAt.After(s.EndTime) {
+		s.nextRunAt = time.Time{}
+	}
+
+	return s.nextRunAt
+}
+
+// Occurrences returns up to count run times of the Reminder whose occurrence
+// is at or after from and, unless until is zero, at or before until.
+// Occurrences follow each other the way the dispatcher materializes
+// schedules: FirstRunAt, then GetNextRunAt of the previous occurrence, and
+// fire at RunAt. Occurrences from the first one stop after MaxOccurrences
+// occurrences, occurrences from a later from stop after the MaxOccurrences -
+// Fired occurrences left to fire.
+func (s *Reminder) Occurrences(from, until time.Time, count int) []time.Time {
+	occurrences := s.walk(from, until, count)
+	for i, occurrence := range occurrences {
+		occurrences[i] = s.RunAt(occurrence)
+	}
+	slices.SortFunc(occurrences, func(a, b time.Time) int { return a.Compare(b) })
+	return occurrences
+}
+
+// walk returns up to count occurrences of the Reminder between from and until
+func (s *Reminder) walk(from, until time.Time, count int) []time.Time {
+	first := s.firstRun()
+	run := s.seek(first, from)
+
+	left := s.MaxOccurrences
+	if !run.Equal(first) {
+		left -= s.Fired
+	}
+	if s.MaxOccurrences > 0 && left <= 0 {
+		return nil
+	}
+
+	var occurrences []time.Time
+	for step := 0; !run.IsZero() && len(occurrences) < count && step < maxOccurrenceSteps; step++ {
+		if !until.IsZero() && run.After(until) {
+			break
+		}
+		occurrences = append(occurrences, run)
+		if s.MaxOccurrences > 0 && len(occurrences) == left {
+			break
+		}
+
+		next := s.nextRun(run)
+		if next.IsZero() || !next.After(run) {
+			break
+		}
+		run = next
+	}
+
+	return occurrences
+}
+
+// seek returns the first occurrence of the Reminder at or after from, not on
+// a skipped date, given its first run. It jumps to from instead of walking
+// the occurrences before it.
+func (s *Reminder) seek(first, from time.Time) time.Time {
+	if first.IsZero() || !first.Before(from) {
+		return first
+	}
+
+	run := s.seekOccurrence(first, from)
+	if !run.IsZero() && s.skipped(run) {
+		return s.nextRun(run)
+	}
+	return run
+}
+
+// seekOccurrence returns the first occurrence of the recurrence at or after
+// from, skipped or not, given an earlier occurrence
+func (s *Reminder) seekOccurrence(first, from time.Time) time.Time {
+	if first.IsZero() || !first.Before(from) {
+		return first
+	}
+
+	run := first
+	switch {
+	case s.recurrence != nil:
+		run = s.recurrence.next(from.Add(-time.Nanosecond).In(s.Location()))
+		if !s.EndTime.IsZero() && run.After(s.EndTime) {
+			return time.Time{}
+		}
+	case s.repeatInterval > 0:
+		// runs up to the end time are the start time plus a multiple of the
+		// interval, the walk starts at the last one not after from
+		last := from
+		if !s.EndTime.IsZero() && last.After(s.EndTime) {
+			last = s.EndTime
+		}
+		run = s.StartTime.Add(last.Sub(s.StartTime) / s.repeatInterval * s.repeatInterval)
+	case len(s.RepeatDaily) > 0:
+		// the next daily run follows the date of the previous one, the walk
+		// starts the day before from
+		run = wallClock(floating(from.In(s.Location())).AddDate(0, 0, -1), s.Location())
+	}
+
+	for step := 0; !run.IsZero() && run.Before(from); step++ {
+		if step == maxOccurrenceSteps {
+			return time.Time{}
+		}
+		run = s.nextOccurrence(run)
+	}
+	return run
+}
+
 func generateRunTimeSequence(lasSeq time.Time, sequence []int) time.Time {
 	for _, day := range sequence {
 		if day < int(time.Sunday) || day > int(time.Saturday) {
@@ -18,6 +18,7 @@ func TestNewReminder(t *testing.T) {
 		endTime      string
 		repeatHourly string
 		repeatDaily  []int
+		opts         []ReminderOption
 	}
 	tests := []struct {
 		name    string
@@ -141,6 +142,7 @@ func TestNewReminder(t *testing.T) {
 				endTime:      mockedTimeNowAfterOneHour,
 				repeatHourly: "20m",
 				repeatDaily:  []int{1, 5, 3},
+				opts:         []ReminderOption{WithRecipients("a@example.com")},
 			},
 			want: &Reminder{
 				ID:             0,
@@ -149,6 +151,7 @@ func TestNewReminder(t *testing.T) {
 				EndTime:        mockedTimeNowAfterOneDayParsed,
 				RepeatHourly:   "20m",
 				RepeatDaily:    []int{1, 3, 5},
+				Recipients:     []string{"a@example.com"},
 				isRoutine:      true,
 				repeatInterval: 20 * time.Minute,
 				nextRunAt:      time.Time{},
@@ -157,6 +160,196 @@ func TestNewReminder(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "invalid cron",
+			args: args{
+				taskID:    1,
+				startTime: mockedTimeNow,
+				opts:      []ReminderOption{WithCron("0 25 * * *")},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "cron combined with repeat hourly",
+			args: args{
+				taskID:       1,
+				startTime:    mockedTimeNow,
+				repeatHourly: "1h",
+				opts:         []ReminderOption{WithCron("@daily")},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "invalid rrule",
+			args: args{
+				taskID:    1,
+				startTime: mockedTimeNow,
+				opts:      []ReminderOption{WithRRule("FREQ=FORTNIGHTLY")},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "rrule without occurrence",
+			args: args{
+				taskID:    1,
+				startTime: mockedTimeNow,
+				opts:      []ReminderOption{WithRRule("FREQ=MINUTELY;BYMONTH=2;BYMONTHDAY=30")},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "rrule whose occurrence is excluded",
+			args: args{
+				taskID:    1,
+				startTime: mockedTimeNow,
+				opts:      []ReminderOption{WithRRule("RRULE:FREQ=DAILY;COUNT=1\nEXDATE:20250720T033823Z")},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "rrule combined with cron",
+			args: args{
+				taskID:    1,
+				startTime: mockedTimeNow,
+				opts:      []ReminderOption{WithCron("@daily"), WithRRule("FREQ=DAILY")},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "invalid timezone",
+			args: args{
+				taskID:    1,
+				startTime: mockedTimeNow,
+				opts:      []ReminderOption{WithTimezone("Mars/Olympus_Mons")},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "invalid repeat monthly",
+			args: args{
+				taskID:    1,
+				startTime: mockedTimeNow,
+				opts:      []ReminderOption{WithRepeatMonthly(32)},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "repeat monthly with repeat daily",
+			args: args{
+				taskID:      1,
+				startTime:   mockedTimeNow,
+				repeatDaily: []int{1},
+				opts:        []ReminderOption{WithRepeatMonthly(1)},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "invalid repeat yearly",
+			args: args{
+				taskID:    1,
+				startTime: mockedTimeNow,
+				opts:      []ReminderOption{WithRepeatYearly("02-30")},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "repeat yearly with cron",
+			args: args{
+				taskID:    1,
+				startTime: mockedTimeNow,
+				opts:      []ReminderOption{WithRepeatYearly("12-25"), WithCron("@daily")},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "negative max occurrences",
+			args: args{
+				taskID:       1,
+				startTime:    mockedTimeNow,
+				repeatHourly: "1h",
+				opts:         []ReminderOption{WithMaxOccurrences(-1)},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "invalid misfire policy",
+			args: args{
+				taskID:    1,
+				startTime: mockedTimeNow,
+				opts:      []ReminderOption{WithMisfirePolicy("fire_twice")},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "invalid recipient",
+			args: args{
+				taskID:    1,
+				startTime: mockedTimeNow,
+				opts:      []ReminderOption{WithRecipients("not an email")},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "email without recipients",
+			args: args{
+				taskID:    1,
+				startTime: mockedTimeNow,
+				opts:      []ReminderOption{WithChannel(ChannelEmail)},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "default channel without recipients",
+			args: args{
+				taskID:    1,
+				startTime: mockedTimeNow,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "log without recipients",
+			args: args{
+				taskID:    1,
+				startTime: mockedTimeNow,
+				opts:      []ReminderOption{WithChannel(ChannelLog)},
+			},
+			want: &Reminder{
+				TaskID:    1,
+				StartTime: mockedTimeNowParsed,
+				Channel:   ChannelLog,
+			},
+			wantErr: false,
+		},
+		{
+			name: "success with recipients",
+			args: args{
+				taskID:    1,
+				startTime: mockedTimeNow,
+				opts:      []ReminderOption{WithRecipients("a@example.com", "B <b@example.com>")},
+			},
+			want: &Reminder{
+				TaskID:     1,
+				StartTime:  mockedTimeNowParsed,
+				Recipients: []string{"a@example.com", "B <b@example.com>"},
+			},
+			wantErr: false,
+		},
 		{
 			name: "success no routine",
 			args: args{
@@ -165,6 +358,7 @@ func TestNewReminder(t *testing.T) {
 				endTime:      "",
 				repeatHourly: "",
 				repeatDaily:  []int{},
+				opts:         []ReminderOption{WithRecipients("a@example.com")},
 			},
 			want: &Reminder{
 				ID:             0,
@@ -173,6 +367,7 @@ func TestNewReminder(t *testing.T) {
 				EndTime:        time.Time{},
 				RepeatHourly:   "",
 				RepeatDaily:    []int{},
+				Recipients:     []string{"a@example.com"},
 				isRoutine:      false,
 				repeatInterval: 0,
 				nextRunAt:      time.Time{},
@@ -184,7 +379,7 @@ func TestNewReminder(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := NewReminder(tt.args.taskID, tt.args.startTime, tt.args.endTime, tt.args.repeatHourly, tt.args.repeatDaily)
+			got, err := NewReminder(tt.args.taskID, tt.args.startTime, tt.args.endTime, tt.args.repeatHourly, tt.args.repeatDaily, tt.args.opts...)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("NewReminder() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -208,6 +403,7 @@ func TestReminder_GetNextRunAt(t *testing.T) {
 		endTime      string
 		repeatHourly string
 		repeatDaily  []int
+		opts         []ReminderOption
 	}
 	type args struct {
 		lastRun time.Time
@@ -286,6 +482,79 @@ func TestReminder_GetNextRunAt(t *testing.T) {
 			},
 			want: mockedTimeNowParsed.AddDate(0, 0, 7),
 		},
+		{
+			name: "repeatDaily without repeatHourly",
+			fields: fields{
+				taskID:      1,
+				startTime:   mockedTimeNow,
+				repeatDaily: []int{1},
+			},
+			args: args{
+				lastRun: time.Time{},
+			},
+			want: mockedTimeNowParsed.AddDate(0, 0, 1),
+		},
+		{
+			name: "cron, last run is empty",
+			fields: fields{
+				taskID:    1,
+				startTime: mockedTimeNow,
+				opts:      []ReminderOption{WithCron("0 9 1 * *")},
+			},
+			args: args{
+				lastRun: time.Time{},
+			},
+			want: time.Date(2025, 8, 1, 9, 0, 0, 0, mockedTimeNowParsed.Location()),
+		},
+		{
+			name: "cron, weekdays at 08:30 and 17:30",
+			fields: fields{
+				taskID:    1,
+				startTime: mockedTimeNow,
+				opts:      []ReminderOption{WithCron("30 8,17 * * 1-5")},
+			},
+			args: args{
+				lastRun: time.Date(2025, 7, 21, 8, 30, 0, 0, mockedTimeNowParsed.Location()),
+			},
+			want: time.Date(2025, 7, 21, 17, 30, 0, 0, mockedTimeNowParsed.Location()),
+		},
+		{
+			name: "cron, next run is after endtime",
+			fields: fields{
+				taskID:    1,
+				startTime: mockedTimeNow,
+				endTime:   mockedTimeNowAfterOneHour,
+				opts:      []ReminderOption{WithCron("@daily")},
+			},
+			args: args{
+				lastRun: time.Time{},
+			},
+			want: time.Time{},
+		},
+		{
+			name: "rrule, last friday of every month",
+			fields: fields{
+				taskID:    1,
+				startTime: mockedTimeNow,
+				opts:      []ReminderOption{WithRRule("FREQ=MONTHLY;BYDAY=-1FR")},
+			},
+			args: args{
+				lastRun: time.Date(2025, 7, 25, 10, 38, 23, 0, mockedTimeNowParsed.Location()),
+			},
+			want: time.Date(2025, 8, 29, 10, 38, 23, 0, mockedTimeNowParsed.Location()),
+		},
+		{
+			name: "rrule, count exhausted",
+			fields: fields{
+				taskID:    1,
+				startTime: mockedTimeNow,
+				opts:      []ReminderOption{WithRRule("FREQ=DAILY;COUNT=2")},
+			},
+			args: args{
+				lastRun: mockedTimeNowParsed.AddDate(0, 0, 1),
+			},
+			want: time.Time{},
+		},
 		{
 			name: "last run is not empty. within endtime, and repeatDaily is empty",
 			fields: fields{
@@ -303,7 +572,7 @@ func TestReminder_GetNextRunAt(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s, err := NewReminder(tt.fields.taskID, tt.fields.startTime, tt.fields.endTime, tt.fields.repeatHourly, tt.fields.repeatDaily)
+			s, err := NewReminder(tt.fields.taskID, tt.fields.startTime, tt.fields.endTime, tt.fields.repeatHourly, tt.fields.repeatDaily, withRecipient(tt.fields.opts...)...)
 			if err != nil {
 				t.Error(err)
 				return
@@ -315,6 +584,427 @@ func TestReminder_GetNextRunAt(t *testing.T) {
 	}
 }
 
+func TestReminder_GetNextRunAt_DST(t *testing.T) {
+	type fields struct {
+		startTime   string
+		repeatDaily []int
+		opts        []ReminderOption
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		lastRun string
+		want    string
+	}{
+		{
+			name: "weekly on monday keeps 09:00 across spring forward",
+			fields: fields{
+				startTime:   "2025-03-24T09:00:00+01:00",
+				repeatDaily: []int{1},
+				opts:        []ReminderOption{WithTimezone("Europe/Berlin")},
+			},
+			lastRun: "2025-03-24T09:00:00+01:00",
+			want:    "2025-03-31T09:00:00+02:00",
+		},
+		{
+			name: "weekly on monday keeps 09:00 across fall back",
+			fields: fields{
+				startTime:   "2025-10-20T09:00:00+02:00",
+				repeatDaily: []int{1},
+				opts:        []ReminderOption{WithTimezone("Europe/Berlin")},
+			},
+			lastRun: "2025-10-20T09:00:00+02:00",
+			want:    "2025-10-27T09:00:00+01:00",
+		},
+		{
+			name: "start time given in utc repeats at the local wall clock",
+			fields: fields{
+				startTime:   "2025-03-07T14:00:00Z",
+				repeatDaily: []int{5},
+				opts:        []ReminderOption{WithTimezone("America/New_York")},
+			},
+			lastRun: "2025-03-07T14:00:00Z",
+			want:    "2025-03-14T09:00:00-04:00",
+		},
+		{
+			name: "without time zone the offset of the start time is kept",
+			fields: fields{
+				startTime:   "2025-03-24T09:00:00+01:00",
+				repeatDaily: []int{1},
+			},
+			lastRun: "2025-03-24T09:00:00+01:00",
+			want:    "2025-03-31T09:00:00+01:00",
+		},
+		{
+			name: "daily run in the skipped hour is shifted forward",
+			fields: fields{
+				startTime:   "2025-03-29T02:30:00+01:00",
+				repeatDaily: []int{0, 1, 2, 3, 4, 5, 6},
+				opts:        []ReminderOption{WithTimezone("Europe/Berlin")},
+			},
+			lastRun: "2025-03-29T02:30:00+01:00",
+			want:    "2025-03-30T03:30:00+02:00",
+		},
+		{
+			name: "cron in the skipped hour is shifted forward",
+			fields: fields{
+				startTime: "2025-03-29T00:00:00+01:00",
+				opts:      []ReminderOption{WithCron("30 2 * * *"), WithTimezone("Europe/Berlin")},
+			},
+			lastRun: "2025-03-29T02:30:00+01:00",
+			want:    "2025-03-30T03:30:00+02:00",
+		},
+		{
+			name: "cron in the repeated hour runs once, at the earlier occurrence",
+			fields: fields{
+				startTime: "2025-10-25T00:00:00+02:00",
+				opts:      []ReminderOption{WithCron("30 2 * * *"), WithTimezone("Europe/Berlin")},
+			},
+			lastRun: "2025-10-25T02:30:00+02:00",
+			want:    "2025-10-26T02:30:00+02:00",
+		},
+		{
+			name: "cron after the repeated hour skips the later occurrence",
+			fields: fields{
+				startTime: "2025-10-25T00:00:00+02:00",
+				opts:      []ReminderOption{WithCron("30 2 * * *"), WithTimezone("Europe/Berlin")},
+			},
+			lastRun: "2025-10-26T02:30:00+02:00",
+			want:    "2025-10-27T02:30:00+01:00",
+		},
+		{
+			name: "rrule keeps the wall clock across DST",
+			fields: fields{
+				startTime: "2025-10-31T17:00:00+01:00",
+				opts:      []ReminderOption{WithRRule("FREQ=MONTHLY;BYDAY=-1FR"), WithTimezone("Europe/Berlin")},
+			},
+			lastRun: "2026-02-27T17:00:00+01:00",
+			want:    "2026-03-27T17:00:00+01:00",
+		},
+		{
+			name: "hourly rrule runs the repeated hour twice",
+			fields: fields{
+				startTime: "2025-10-26T01:00:00+02:00",
+				opts:      []ReminderOption{WithRRule("FREQ=HOURLY"), WithTimezone("Europe/Berlin")},
+			},
+			lastRun: "2025-10-26T02:00:00+02:00",
+			want:    "2025-10-26T02:00:00+01:00",
+		},
+		{
+			name: "rrule in the southern hemisphere",
+			fields: fields{
+				startTime: "2025-09-29T08:00:00+10:00",
+				opts:      []ReminderOption{WithRRule("FREQ=WEEKLY;BYDAY=MO"), WithTimezone("Australia/Sydney")},
+			},
+			lastRun: "2025-09-29T08:00:00+10:00",
+			want:    "2025-10-06T08:00:00+11:00",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewReminder(1, tt.fields.startTime, "", "", tt.fields.repeatDaily, withRecipient(tt.fields.opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lastRun, _ := time.Parse(time.RFC3339, tt.lastRun)
+			if got := s.GetNextRunAt(lastRun).Format(time.RFC3339); got != tt.want {
+				t.Errorf("Reminder.GetNextRunAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReminder_FirstRunAt(t *testing.T) {
+	mockedTimeNow := "2025-07-20T10:38:23+07:00"
+	mockedTimeNowParsed, _ := time.Parse(time.RFC3339, mockedTimeNow)
+	type fields struct {
+		startTime string
+		endTime   string
+		opts      []ReminderOption
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   time.Time
+	}{
+		{
+			name:   "start time of a non cron reminder",
+			fields: fields{startTime: mockedTimeNow},
+			want:   mockedTimeNowParsed,
+		},
+		{
+			name: "first cron match after start time",
+			fields: fields{
+				startTime: mockedTimeNow,
+				opts:      []ReminderOption{WithCron("0 9 * * *")},
+			},
+			want: time.Date(2025, 7, 21, 9, 0, 0, 0, mockedTimeNowParsed.Location()),
+		},
+		{
+			name: "start time matching the cron",
+			fields: fields{
+				startTime: "2025-07-21T09:00:00+07:00",
+				opts:      []ReminderOption{WithCron("0 9 * * *")},
+			},
+			want: time.Date(2025, 7, 21, 9, 0, 0, 0, mockedTimeNowParsed.Location()),
+		},
+		{
+			name: "first cron match after end time",
+			fields: fields{
+				startTime: mockedTimeNow,
+				endTime:   "2025-07-20T23:00:00+07:00",
+				opts:      []ReminderOption{WithCron("0 9 * * *")},
+			},
+			want: time.Time{},
+		},
+		{
+			name: "first rrule occurrence after start time",
+			fields: fields{
+				startTime: mockedTimeNow,
+				opts:      []ReminderOption{WithRRule("RRULE:FREQ=MONTHLY;BYDAY=-1FR")},
+			},
+			want: time.Date(2025, 7, 25, 10, 38, 23, 0, mockedTimeNowParsed.Location()),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewReminder(1, tt.fields.startTime, tt.fields.endTime, "", nil, withRecipient(tt.fields.opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.FirstRunAt(); !got.Equal(tt.want) {
+				t.Errorf("Reminder.FirstRunAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReminder_Occurrences(t *testing.T) {
+	type fields struct {
+		startTime    string
+		endTime      string
+		repeatHourly string
+		repeatDaily  []int
+		opts         []ReminderOption
+	}
+	type args struct {
+		from  string
+		until string
+		count int
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   []string
+	}{
+		{
+			name:   "one-off reminder",
+			fields: fields{startTime: "2025-07-21T09:00:00+07:00"},
+			args:   args{from: "2025-07-20T00:00:00+07:00", count: 5},
+			want:   []string{"2025-07-21T09:00:00+07:00"},
+		},
+		{
+			name:   "one-off reminder before from",
+			fields: fields{startTime: "2025-07-21T09:00:00+07:00"},
+			args:   args{from: "2025-07-22T00:00:00+07:00", count: 5},
+			want:   nil,
+		},
+		{
+			name: "repeat hourly within end time",
+			fields: fields{
+				startTime:    "2025-07-21T09:00:00+07:00",
+				endTime:      "2025-07-21T11:00:00+07:00",
+				repeatHourly: "45m",
+			},
+			args: args{from: "2025-07-21T00:00:00+07:00", count: 5},
+			want: []string{"2025-07-21T09:00:00+07:00", "2025-07-21T09:45:00+07:00", "2025-07-21T10:30:00+07:00"},
+		},
+		{
+			name: "repeat daily limited by count",
+			fields: fields{
+				startTime:   "2025-07-21T09:00:00+07:00",
+				repeatDaily: []int{1, 3},
+			},
+			args: args{from: "2025-07-22T00:00:00+07:00", count: 3},
+			want: []string{"2025-07-23T09:00:00+07:00", "2025-07-28T09:00:00+07:00", "2025-07-30T09:00:00+07:00"},
+		},
+		{
+			name: "cron limited by until",
+			fields: fields{
+				startTime: "2025-07-21T00:00:00+07:00",
+				opts:      []ReminderOption{WithCron("0 9 * * *")},
+			},
+			args: args{from: "2025-07-21T00:00:00+07:00", until: "2025-07-23T09:00:00+07:00", count: 10},
+			want: []string{"2025-07-21T09:00:00+07:00", "2025-07-22T09:00:00+07:00", "2025-07-23T09:00:00+07:00"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewReminder(1, tt.fields.startTime, tt.fields.endTime, tt.fields.repeatHourly, tt.fields.repeatDaily, withRecipient(tt.fields.opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			from, _ := time.Parse(time.RFC3339, tt.args.from)
+			var until time.Time
+			if tt.args.until != "" {
+				until, _ = time.Parse(time.RFC3339, tt.args.until)
+			}
+
+			var got []string
+			for _, occurrence := range s.Occurrences(from, until, tt.args.count) {
+				got = append(got, occurrence.Format(time.RFC3339))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Reminder.Occurrences() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReminder_Occurrences_farFrom(t *testing.T) {
+	start := "2025-01-01T00:00:00Z"
+	from := time.Date(2026, 10, 1, 0, 2, 30, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		repeatHourly   string
+		opts           []ReminderOption
+		fired          int
+		maxOccurrences int
+		want           []time.Time
+	}{
+		{
+			name:         "every 5 minutes",
+			repeatHourly: "5m",
+			want:         []time.Time{from.Add(150 * time.Second), from.Add(450 * time.Second), from.Add(750 * time.Second)},
+		},
+		{
+			name: "every second cron",
+			opts: []ReminderOption{WithCron("* * * * * *")},
+			want: []time.Time{from, from.Add(time.Second), from.Add(2 * time.Second)},
+		},
+		{
+			name: "minutely rrule",
+			opts: []ReminderOption{WithRRule("FREQ=MINUTELY")},
+			want: []time.Time{from.Add(30 * time.Second), from.Add(90 * time.Second), from.Add(150 * time.Second)},
+		},
+		{
+			name:           "runs left to fire",
+			repeatHourly:   "5m",
+			fired:          1000,
+			maxOccurrences: 1002,
+			want:           []time.Time{from.Add(150 * time.Second), from.Add(450 * time.Second)},
+		},
+		{
+			name:           "fired max occurrences",
+			repeatHourly:   "5m",
+			fired:          1000,
+			maxOccurrences: 1000,
+			want:           nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append(tt.opts, WithMaxOccurrences(tt.maxOccurrences))
+			reminder, err := NewReminder(1, start, "", tt.repeatHourly, nil, withRecipient(opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			reminder.Fired = tt.fired
+
+			got := reminder.Occurrences(from, time.Time{}, 3)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Reminder.Occurrences() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) {
+					t.Errorf("Reminder.Occurrences()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReminder_MaxOccurrences(t *testing.T) {
+	start := time.Date(2025, 7, 20, 9, 0, 0, 0, time.UTC)
+	hours := func(n ...int) []time.Time {
+		var times []time.Time
+		for _, h := range n {
+			times = append(times, start.Add(time.Duration(h)*time.Hour))
+		}
+		return times
+	}
+
+	tests := []struct {
+		name            string
+		maxOccurrences  int
+		fired           int
+		wantNext        time.Time
+		wantFirst       time.Time
+		wantOccurrences []time.Time
+	}{
+		{
+			name:            "unlimited",
+			maxOccurrences:  0,
+			fired:           5,
+			wantFirst:       start,
+			wantNext:        start.Add(2 * time.Hour),
+			wantOccurrences: hours(0, 1, 2, 3, 4),
+		},
+		{
+			name:            "not fired yet",
+			maxOccurrences:  3,
+			fired:           0,
+			wantFirst:       start,
+			wantNext:        start.Add(2 * time.Hour),
+			wantOccurrences: hours(0, 1, 2),
+		},
+		{
+			name:            "one run left",
+			maxOccurrences:  3,
+			fired:           2,
+			wantFirst:       start,
+			wantNext:        start.Add(2 * time.Hour),
+			wantOccurrences: hours(0, 1, 2),
+		},
+		{
+			name:            "count reached",
+			maxOccurrences:  3,
+			fired:           3,
+			wantFirst:       time.Time{},
+			wantNext:        time.Time{},
+			wantOccurrences: hours(0, 1, 2),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reminder, err := NewReminder(1, start.Format(time.RFC3339), "", "1h", nil, withRecipient(WithMaxOccurrences(tt.maxOccurrences))...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			reminder.Fired = tt.fired
+
+			if got := reminder.FirstRunAt(); !got.Equal(tt.wantFirst) {
+				t.Errorf("Reminder.FirstRunAt() = %v, want %v", got, tt.wantFirst)
+			}
+			if got := reminder.GetNextRunAt(start.Add(time.Hour)); !got.Equal(tt.wantNext) {
+				t.Errorf("Reminder.GetNextRunAt() = %v, want %v", got, tt.wantNext)
+			}
+
+			got := reminder.Occurrences(start, time.Time{}, 5)
+			if len(got) != len(tt.wantOccurrences) {
+				t.Fatalf("Reminder.Occurrences() = %v, want %v", got, tt.wantOccurrences)
+			}
+			for i := range got {
+				if !got[i].Equal(tt.wantOccurrences[i]) {
+					t.Errorf("Reminder.Occurrences()[%d] = %v, want %v", i, got[i], tt.wantOccurrences[i])
+				}
+			}
+		})
+	}
+}
+
 func TestGenerateRunTimeSequence(t *testing.T) {
 	type args struct {
 		lasSeq time.Time
@@ -390,3 +1080,8 @@ func TestGenerateRunTimeSequence(t *testing.T) {
 		})
 	}
 }
+
+// withRecipient prepends a recipient to opts, email reminders require one
+func withRecipient(opts ...ReminderOption) []ReminderOption {
+	return append([]ReminderOption{WithRecipients("team@example.com")}, opts...)
+}
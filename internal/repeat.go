@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// dateRepeat repeats on a day of the month, every month or once a year, at
+// the wall clock of the start time. Days past the end of a month are clamped
+// to its last day, e.g., the 31st runs on April 30th and February 28th, or
+// 29th in leap years.
+type dateRepeat struct {
+	// month is the month of yearly repeats, zero repeats every month
+	month time.Month
+	day   int
+	// clock is the wall clock of the runs, as a time on January 1st, year 1
+	clock time.Time
+}
+
+func newDateRepeat(month time.Month, day int, start time.Time) *dateRepeat {
+	return &dateRepeat{
+		month: month,
+		day:   day,
+		clock: time.Date(1, time.January, 1, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), time.UTC),
+	}
+}
+
+// parseRepeatYearly parses a month and day, e.g., "12-25". February 29th is
+// accepted, it runs on February 28th outside leap years.
+func parseRepeatYearly(value string) (time.Month, int, error) {
+	if len(value) != 5 || value[2] != '-' {
+		return 0, 0, fmt.Errorf("%q must be MM-DD", value)
+	}
+
+	month, err := strconv.Atoi(value[:2])
+	if err != nil || month < int(time.January) || month > int(time.December) {
+		return 0, 0, fmt.Errorf("invalid month in %q", value)
+	}
+
+	day, err := strconv.Atoi(value[3:])
+	// 2000 is a leap year, every day that exists in some year is accepted
+	if err != nil || day < 1 || day > daysIn(2000, time.Month(month)) {
+		return 0, 0, fmt.Errorf("invalid day in %q", value)
+	}
+
+	return time.Month(month), day, nil
+}
+
+// next returns the first run strictly after t, in the location of t
+func (d *dateRepeat) next(t time.Time) time.Time {
+	loc := t.Location()
+	wall := floating(t)
+
+	// the run in the month of t may be before t, a yearly repeat runs again
+	// within the following 12 months
+	year, month := wall.Year(), wall.Month()
+	for i := 0; i <= 12; i++ {
+		if d.month == 0 || d.month == month {
+			day := min(d.day, daysIn(year, month))
+			run := wallClock(time.Date(year, month, day, d.clock.Hour(), d.clock.Minute(), d.clock.Second(), d.clock.Nanosecond(), time.UTC), loc)
+			if run.After(t) {
+				return run
+			}
+		}
+
+		month++
+		if month > time.December {
+			month = time.January
+			year++
+		}
+	}
+
+	return time.Time{}
+}
+
+// daysIn returns the number of days of month in year
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
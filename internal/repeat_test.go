@@ -0,0 +1,182 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRepeatYearly(t *testing.T) {
+	tests := []struct {
+		value     string
+		wantMonth time.Month
+		wantDay   int
+		wantErr   bool
+	}{
+		{value: "12-25", wantMonth: time.December, wantDay: 25},
+		{value: "01-01", wantMonth: time.January, wantDay: 1},
+		{value: "02-29", wantMonth: time.February, wantDay: 29},
+		{value: "02-30", wantErr: true},
+		{value: "04-31", wantErr: true},
+		{value: "13-01", wantErr: true},
+		{value: "00-10", wantErr: true},
+		{value: "12-00", wantErr: true},
+		{value: "1-1", wantErr: true},
+		{value: "12/25", wantErr: true},
+		{value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			month, day, err := parseRepeatYearly(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRepeatYearly() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if month != tt.wantMonth || day != tt.wantDay {
+				t.Errorf("parseRepeatYearly() = %v %d, want %v %d", month, day, tt.wantMonth, tt.wantDay)
+			}
+		})
+	}
+}
+
+func TestReminder_RepeatMonthlyYearly(t *testing.T) {
+	jakarta, _ := time.LoadLocation("Asia/Jakarta")
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	date := func(loc *time.Location, year int, month time.Month, day, hour int) time.Time {
+		return time.Date(year, month, day, hour, 0, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name  string
+		start string
+		end   string
+		opts  []ReminderOption
+		want  []time.Time
+	}{
+		{
+			name:  "monthly on the 1st starts on the next 1st",
+			start: "2025-01-15T09:00:00+07:00",
+			opts:  []ReminderOption{WithRepeatMonthly(1)},
+			want: []time.Time{
+				date(jakarta, 2025, time.February, 1, 9),
+				date(jakarta, 2025, time.March, 1, 9),
+				date(jakarta, 2025, time.April, 1, 9),
+			},
+		},
+		{
+			name:  "monthly starts on the start time when it matches",
+			start: "2025-01-01T09:00:00+07:00",
+			opts:  []ReminderOption{WithRepeatMonthly(1)},
+			want: []time.Time{
+				date(jakarta, 2025, time.January, 1, 9),
+				date(jakarta, 2025, time.February, 1, 9),
+				date(jakarta, 2025, time.March, 1, 9),
+			},
+		},
+		{
+			name:  "monthly on the 31st is clamped to the end of the month",
+			start: "2025-01-31T09:00:00+07:00",
+			opts:  []ReminderOption{WithRepeatMonthly(31)},
+			want: []time.Time{
+				date(jakarta, 2025, time.January, 31, 9),
+				date(jakarta, 2025, time.February, 28, 9),
+				date(jakarta, 2025, time.March, 31, 9),
+				date(jakarta, 2025, time.April, 30, 9),
+			},
+		},
+		{
+			name:  "monthly on the 30th in a leap year february",
+			start: "2024-01-30T09:00:00+07:00",
+			opts:  []ReminderOption{WithRepeatMonthly(30)},
+			want: []time.Time{
+				date(jakarta, 2024, time.January, 30, 9),
+				date(jakarta, 2024, time.February, 29, 9),
+				date(jakarta, 2024, time.March, 30, 9),
+			},
+		},
+		{
+			name:  "monthly on the 29th does not run twice at the end of february",
+			start: "2025-02-28T09:00:00+07:00",
+			opts:  []ReminderOption{WithRepeatMonthly(29)},
+			want: []time.Time{
+				date(jakarta, 2025, time.February, 28, 9),
+				date(jakarta, 2025, time.March, 29, 9),
+				date(jakarta, 2025, time.April, 29, 9),
+			},
+		},
+		{
+			name:  "monthly stops at end time",
+			start: "2025-01-01T09:00:00+07:00",
+			end:   "2025-02-15T00:00:00+07:00",
+			opts:  []ReminderOption{WithRepeatMonthly(1)},
+			want: []time.Time{
+				date(jakarta, 2025, time.January, 1, 9),
+				date(jakarta, 2025, time.February, 1, 9),
+			},
+		},
+		{
+			name:  "monthly keeps the wall clock across DST",
+			start: "2025-03-01T09:00:00+01:00",
+			opts:  []ReminderOption{WithRepeatMonthly(1), WithTimezone("Europe/Berlin")},
+			want: []time.Time{
+				date(berlin, 2025, time.March, 1, 9),
+				date(berlin, 2025, time.April, 1, 9),
+				date(berlin, 2025, time.May, 1, 9),
+			},
+		},
+		{
+			name:  "yearly",
+			start: "2025-07-20T09:00:00+07:00",
+			opts:  []ReminderOption{WithRepeatYearly("12-25")},
+			want: []time.Time{
+				date(jakarta, 2025, time.December, 25, 9),
+				date(jakarta, 2026, time.December, 25, 9),
+				date(jakarta, 2027, time.December, 25, 9),
+			},
+		},
+		{
+			name:  "yearly on february 29th outside leap years",
+			start: "2023-01-01T09:00:00+07:00",
+			opts:  []ReminderOption{WithRepeatYearly("02-29")},
+			want: []time.Time{
+				date(jakarta, 2023, time.February, 28, 9),
+				date(jakarta, 2024, time.February, 29, 9),
+				date(jakarta, 2025, time.February, 28, 9),
+				date(jakarta, 2026, time.February, 28, 9),
+				date(jakarta, 2027, time.February, 28, 9),
+				date(jakarta, 2028, time.February, 29, 9),
+			},
+		},
+		{
+			name:  "yearly earlier in the month of the start time",
+			start: "2025-12-26T09:00:00+07:00",
+			opts:  []ReminderOption{WithRepeatYearly("12-25")},
+			want: []time.Time{
+				date(jakarta, 2026, time.December, 25, 9),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reminder, err := NewReminder(1, tt.start, tt.end, "", nil, withRecipient(tt.opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			count := len(tt.want)
+			if tt.end != "" {
+				// a reminder with an end time must not run past it
+				count++
+			}
+
+			until := time.Date(2028, time.December, 31, 0, 0, 0, 0, time.UTC)
+			got := reminder.Occurrences(time.Time{}, until, count)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Reminder.Occurrences() = %v, want %v", got, tt.want)
+			}
+			for i := range tt.want {
+				if !got[i].Equal(tt.want[i]) {
+					t.Errorf("Reminder.Occurrences()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package rest
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/elangreza/scheduler/internal/smtpsink"
+)
+
+type (
+	// devMail holds the emails captured by the development SMTP sink
+	devMail interface {
+		Messages() []smtpsink.Message
+		Message(id int64) (*smtpsink.Message, error)
+		Clear()
+	}
+
+	// DevMailHandler serves the emails captured by the development SMTP
+	// sink, as the /dev/mail page and as JSON
+	DevMailHandler struct {
+		mail devMail
+	}
+)
+
+func NewDevMailHandler(mail devMail) *DevMailHandler {
+	return &DevMailHandler{mail: mail}
+}
+
+// PageHandler renders the captured emails, the most recent first (expects
+// /dev/mail)
+func (h *DevMailHandler) PageHandler(w http.ResponseWriter, r *http.Request) {
+	tmpl, err := template.ParseFiles("templates/devmail.html")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	tmpl.Execute(w, h.mail.Messages())
+}
+
+// ListMessagesHandler returns the captured emails, the most recent first
+// (expects /dev/mail/messages)
+func (h *DevMailHandler) ListMessagesHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.mail.Messages())
+}
+
+// GetMessageHandler returns a captured email by id (expects
+// /dev/mail/messages/{id})
+func (h *DevMailHandler) GetMessageHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	msg, err := h.mail.Message(id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, msg)
+}
+
+// RawMessageHandler returns a captured email as it was sent (expects
+// /dev/mail/messages/{id}/raw)
+func (h *DevMailHandler) RawMessageHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	msg, err := h.mail.Message(id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write(msg.Raw)
+}
+
+// ClearMessagesHandler drops the captured emails (expects
+// /dev/mail/messages)
+func (h *DevMailHandler) ClearMessagesHandler(w http.ResponseWriter, r *http.Request) {
+	h.mail.Clear()
+	w.WriteHeader(http.StatusNoContent)
+}
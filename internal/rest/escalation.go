@@ -0,0 +1,92 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/elangreza/scheduler/internal"
+)
+
+// ListEscalationPoliciesHandler returns all escalation policies as JSON
+func (h *Handler) ListEscalationPoliciesHandler(w http.ResponseWriter, r *http.Request) {
+	policies, err := h.svc.ListEscalationPolicies(r.Context())
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, policies)
+}
+
+// CreateEscalationPolicyHandler creates an escalation policy (expects a JSON body)
+func (h *Handler) CreateEscalationPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	var req internal.CreateEscalationPolicyParams
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	policy, err := h.svc.CreateEscalationPolicy(r.Context(), req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, policy)
+}
+
+// GetEscalationPolicyHandler returns an escalation policy by id (expects /escalation-policies/{id})
+func (h *Handler) GetEscalationPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	policy, err := h.svc.GetEscalationPolicy(r.Context(), id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, policy)
+}
+
+// UpdateEscalationPolicyHandler replaces an escalation policy by id (expects /escalation-policies/{id}, and JSON body)
+func (h *Handler) UpdateEscalationPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var req internal.UpdateEscalationPolicyParams
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	policy, err := h.svc.UpdateEscalationPolicy(r.Context(), id, req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, policy)
+}
+
+// DeleteEscalationPolicyHandler deletes an escalation policy by id (expects /escalation-policies/{id})
+func (h *Handler) DeleteEscalationPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.svc.DeleteEscalationPolicy(r.Context(), id); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"html/template"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/elangreza/scheduler/internal"
 )
@@ -20,8 +22,8 @@ func (h *Handler) RootHandler(w http.ResponseWriter, r *http.Request) {
 	tmpl.Execute(w, nil)
 }
 
-func NewHandler(svc svc) *Handler {
-	return &Handler{svc: svc}
+func NewHandler(svc svc, links actionLinks) *Handler {
+	return &Handler{svc: svc, links: links}
 }
 
 type (
@@ -30,10 +32,45 @@ type (
 		ListTask(ctx context.Context) ([]internal.Task, error)
 		DeleteTask(ctx context.Context, id int) error
 		UpdateTask(ctx context.Context, id int, req internal.UpdateTaskParams) error
+
+		CreateReminder(ctx context.Context, taskID int64, req internal.CreateReminderParams) (*internal.Reminder, error)
+		GetReminder(ctx context.Context, id int64) (*internal.Reminder, error)
+		ListReminders(ctx context.Context, taskID int64) ([]internal.Reminder, error)
+		UpdateReminder(ctx context.Context, id int64, req internal.UpdateReminderParams) (*internal.Reminder, error)
+		DeleteReminder(ctx context.Context, id int64) error
+
+		CreateReminderException(ctx context.Context, reminderID int64, req internal.CreateReminderExceptionParams) (*internal.ReminderException, error)
+		ListReminderExceptions(ctx context.Context, reminderID int64) ([]internal.ReminderException, error)
+		DeleteReminderException(ctx context.Context, reminderID, id int64) error
+
+		ListCalendars(ctx context.Context) []internal.Calendar
+		ListOccurrences(ctx context.Context, id int64, req internal.OccurrenceParams) ([]time.Time, error)
+		PreviewOccurrences(ctx context.Context, reminderReq internal.CreateReminderParams, req internal.OccurrenceParams) ([]time.Time, error)
+
+		GetSchedule(ctx context.Context, id int64) (*internal.Schedule, error)
+		AcknowledgeSchedule(ctx context.Context, id int64) (*internal.Schedule, error)
+		SnoozeSchedule(ctx context.Context, id int64, req internal.SnoozeScheduleParams) (*internal.Schedule, error)
+
+		CreateEscalationPolicy(ctx context.Context, req internal.CreateEscalationPolicyParams) (*internal.EscalationPolicy, error)
+		GetEscalationPolicy(ctx context.Context, id int64) (*internal.EscalationPolicy, error)
+		ListEscalationPolicies(ctx context.Context) ([]internal.EscalationPolicy, error)
+		UpdateEscalationPolicy(ctx context.Context, id int64, req internal.UpdateEscalationPolicyParams) (*internal.EscalationPolicy, error)
+		DeleteEscalationPolicy(ctx context.Context, id int64) error
+
+		PutQuietHours(ctx context.Context, recipient string, req internal.PutQuietHoursParams) (*internal.QuietHours, error)
+		GetQuietHours(ctx context.Context, recipient string) (*internal.QuietHours, error)
+		ListQuietHours(ctx context.Context) ([]internal.QuietHours, error)
+		DeleteQuietHours(ctx context.Context, recipient string) error
+	}
+
+	// actionLinks verifies the signed one-click links of notifications
+	actionLinks interface {
+		Verify(action string, scheduleID int64, query url.Values) error
 	}
 
 	Handler struct {
 		svc
+		links actionLinks
 	}
 )
 
@@ -85,7 +122,7 @@ func (h *Handler) UpdateTaskHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err := h.svc.UpdateTask(r.Context(), id, req); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -100,7 +137,7 @@ func (h *Handler) CreateTask(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		if err := h.svc.CreateTask(r.Context(), req); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeServiceError(w, err)
 			return
 		}
 		w.WriteHeader(http.StatusCreated)
@@ -0,0 +1,58 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/elangreza/scheduler/internal"
+)
+
+// ListQuietHoursHandler returns the quiet hours of every recipient as JSON
+func (h *Handler) ListQuietHoursHandler(w http.ResponseWriter, r *http.Request) {
+	list, err := h.svc.ListQuietHours(r.Context())
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, list)
+}
+
+// GetQuietHoursHandler returns the quiet hours of a recipient (expects /quiet-hours/{recipient})
+func (h *Handler) GetQuietHoursHandler(w http.ResponseWriter, r *http.Request) {
+	quietHours, err := h.svc.GetQuietHours(r.Context(), r.PathValue("recipient"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, quietHours)
+}
+
+// PutQuietHoursHandler sets the quiet hours of a recipient (expects /quiet-hours/{recipient}, and JSON body)
+func (h *Handler) PutQuietHoursHandler(w http.ResponseWriter, r *http.Request) {
+	var req internal.PutQuietHoursParams
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	quietHours, err := h.svc.PutQuietHours(r.Context(), r.PathValue("recipient"), req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, quietHours)
+}
+
+// DeleteQuietHoursHandler removes the quiet hours of a recipient, the global
+// quiet hours apply to it again (expects /quiet-hours/{recipient})
+func (h *Handler) DeleteQuietHoursHandler(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.DeleteQuietHours(r.Context(), r.PathValue("recipient")); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
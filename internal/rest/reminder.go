@@ -0,0 +1,300 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/elangreza/scheduler/internal"
+)
+
+// ListReminderHandler returns all reminders of a task as JSON (expects /tasks/{id}/reminders)
+func (h *Handler) ListReminderHandler(w http.ResponseWriter, r *http.Request) {
+	taskID, err := pathID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	reminders, err := h.svc.ListReminders(r.Context(), taskID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, reminders)
+}
+
+// CreateReminderHandler creates a reminder for a task (expects /tasks/{id}/reminders, and JSON body)
+func (h *Handler) CreateReminderHandler(w http.ResponseWriter, r *http.Request) {
+	taskID, err := pathID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var req internal.CreateReminderParams
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	reminder, err := h.svc.CreateReminder(r.Context(), taskID, req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, reminder)
+}
+
+// GetReminderHandler returns a reminder by id (expects /reminders/{id})
+func (h *Handler) GetReminderHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	reminder, err := h.svc.GetReminder(r.Context(), id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, reminder)
+}
+
+// UpdateReminderHandler replaces a reminder by id (expects /reminders/{id}, and JSON body)
+func (h *Handler) UpdateReminderHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var req internal.UpdateReminderParams
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	reminder, err := h.svc.UpdateReminder(r.Context(), id, req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, reminder)
+}
+
+// DeleteReminderHandler deletes a reminder by id (expects /reminders/{id})
+func (h *Handler) DeleteReminderHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.svc.DeleteReminder(r.Context(), id); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListOccurrencesHandler returns the upcoming run times of a reminder (expects
+// /reminders/{id}/occurrences, and optional from, until and count query
+// parameters)
+func (h *Handler) ListOccurrencesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	req, err := occurrenceParams(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	occurrences, err := h.svc.ListOccurrences(r.Context(), id, req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, occurrences)
+}
+
+// PreviewOccurrencesHandler returns the run times of an unsaved reminder
+// without creating it (expects /reminders/occurrences, a reminder JSON body,
+// and optional from, until and count query parameters)
+func (h *Handler) PreviewOccurrencesHandler(w http.ResponseWriter, r *http.Request) {
+	req, err := occurrenceParams(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var reminderReq internal.CreateReminderParams
+	if err := json.NewDecoder(r.Body).Decode(&reminderReq); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	occurrences, err := h.svc.PreviewOccurrences(r.Context(), reminderReq, req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, occurrences)
+}
+
+// occurrenceParams parses the from and until (RFC3339) and count query parameters
+func occurrenceParams(r *http.Request) (internal.OccurrenceParams, error) {
+	var (
+		req   internal.OccurrenceParams
+		err   error
+		query = r.URL.Query()
+	)
+
+	if from := query.Get("from"); from != "" {
+		req.From, err = time.Parse(time.RFC3339, from)
+		if err != nil {
+			return req, fmt.Errorf("invalid from: %s", from)
+		}
+	}
+
+	if until := query.Get("until"); until != "" {
+		req.Until, err = time.Parse(time.RFC3339, until)
+		if err != nil {
+			return req, fmt.Errorf("invalid until: %s", until)
+		}
+	}
+
+	if count := query.Get("count"); count != "" {
+		req.Count, err = strconv.Atoi(count)
+		if err != nil {
+			return req, fmt.Errorf("invalid count: %s", count)
+		}
+	}
+
+	return req, nil
+}
+
+// ListReminderExceptionsHandler returns the exceptions of a reminder as JSON (expects /reminders/{id}/exceptions)
+func (h *Handler) ListReminderExceptionsHandler(w http.ResponseWriter, r *http.Request) {
+	reminderID, err := pathID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	exceptions, err := h.svc.ListReminderExceptions(r.Context(), reminderID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, exceptions)
+}
+
+// CreateReminderExceptionHandler skips a date or overrides an occurrence of a reminder (expects /reminders/{id}/exceptions, and JSON body)
+func (h *Handler) CreateReminderExceptionHandler(w http.ResponseWriter, r *http.Request) {
+	reminderID, err := pathID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var req internal.CreateReminderExceptionParams
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	exception, err := h.svc.CreateReminderException(r.Context(), reminderID, req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, exception)
+}
+
+// DeleteReminderExceptionHandler deletes an exception of a reminder (expects /reminders/{id}/exceptions/{exceptionID})
+func (h *Handler) DeleteReminderExceptionHandler(w http.ResponseWriter, r *http.Request) {
+	reminderID, err := pathID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	id, err := pathInt64(r, "exceptionID")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.svc.DeleteReminderException(r.Context(), reminderID, id); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListCalendarsHandler returns the holiday calendars reminders can reference (expects /calendars)
+func (h *Handler) ListCalendarsHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.svc.ListCalendars(r.Context()))
+}
+
+func pathID(r *http.Request) (int64, error) {
+	return pathInt64(r, "id")
+}
+
+func pathInt64(r *http.Request, name string) (int64, error) {
+	value := r.PathValue(name)
+	if value == "" {
+		return 0, fmt.Errorf("missing %s", name)
+	}
+
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %s", name, value)
+	}
+
+	return id, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// writeServiceError maps errors returned by the service to their HTTP status
+func writeServiceError(w http.ResponseWriter, err error) {
+	var validationErr internal.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		writeError(w, http.StatusBadRequest, err)
+	case errors.Is(err, internal.ErrNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, internal.ErrConflict):
+		writeError(w, http.StatusConflict, err)
+	default:
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
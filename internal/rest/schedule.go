@@ -0,0 +1,204 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/elangreza/scheduler/internal"
+)
+
+// GetScheduleHandler returns a schedule by id (expects /schedules/{id})
+func (h *Handler) GetScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	schedule, err := h.svc.GetSchedule(r.Context(), id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, schedule)
+}
+
+// AcknowledgeScheduleHandler acknowledges a fired schedule (expects
+// /schedules/{id}/acknowledge)
+func (h *Handler) AcknowledgeScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	schedule, err := h.svc.AcknowledgeSchedule(r.Context(), id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, schedule)
+}
+
+// SnoozeScheduleHandler snoozes a fired schedule and returns the snooze
+// (expects /schedules/{id}/snooze, and JSON body)
+func (h *Handler) SnoozeScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var req internal.SnoozeScheduleParams
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	snooze, err := h.svc.SnoozeSchedule(r.Context(), id, req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, snooze)
+}
+
+// AcknowledgeLinkHandler renders the confirmation of the signed link of a
+// notification, links are opened by mail scanners too so only the confirmed
+// form acknowledges the schedule (expects
+// /schedules/{id}/acknowledge?expires=&token=)
+func (h *Handler) AcknowledgeLinkHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.verifyLink(w, r, internal.ActionAcknowledge, r.URL.Query())
+	if !ok {
+		return
+	}
+
+	writeConfirm(w, id, internal.ActionAcknowledge, r.URL.Query(), "Acknowledge reminder", "You will not be reminded of it again.", "Acknowledge")
+}
+
+// ConfirmAcknowledgeHandler acknowledges a fired schedule from the confirmed
+// link of its notification (expects /schedules/{id}/acknowledge/confirm, and
+// the signed link parameters as form)
+func (h *Handler) ConfirmAcknowledgeHandler(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeAction(w, http.StatusBadRequest, "Invalid link", err.Error())
+		return
+	}
+
+	id, ok := h.verifyLink(w, r, internal.ActionAcknowledge, r.PostForm)
+	if !ok {
+		return
+	}
+
+	if _, err := h.svc.AcknowledgeSchedule(r.Context(), id); err != nil {
+		writeActionError(w, err)
+		return
+	}
+
+	writeAction(w, http.StatusOK, "Reminder acknowledged", "You will not be reminded of it again.")
+}
+
+// SnoozeLinkHandler renders the confirmation of the signed snooze link of a
+// notification, only the confirmed form snoozes the schedule (expects
+// /schedules/{id}/snooze?duration=&expires=&token=)
+func (h *Handler) SnoozeLinkHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.verifyLink(w, r, internal.ActionSnooze, r.URL.Query())
+	if !ok {
+		return
+	}
+
+	writeConfirm(w, id, internal.ActionSnooze, r.URL.Query(), "Snooze reminder", fmt.Sprintf("You will be reminded again in %s.", r.URL.Query().Get("duration")), "Snooze")
+}
+
+// ConfirmSnoozeHandler snoozes a fired schedule from the confirmed snooze link
+// of its notification (expects /schedules/{id}/snooze/confirm, and the signed
+// link parameters as form)
+func (h *Handler) ConfirmSnoozeHandler(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeAction(w, http.StatusBadRequest, "Invalid link", err.Error())
+		return
+	}
+
+	id, ok := h.verifyLink(w, r, internal.ActionSnooze, r.PostForm)
+	if !ok {
+		return
+	}
+
+	snooze, err := h.svc.SnoozeSchedule(r.Context(), id, internal.SnoozeScheduleParams{Duration: r.PostForm.Get("duration")})
+	if err != nil {
+		writeActionError(w, err)
+		return
+	}
+
+	writeAction(w, http.StatusOK, "Reminder snoozed", fmt.Sprintf("You will be reminded again at %s.", snooze.NotifyAt.Format(time.RFC1123)))
+}
+
+// verifyLink checks the signed parameters of a one-click link, it writes the
+// error page and returns false when the link is invalid
+func (h *Handler) verifyLink(w http.ResponseWriter, r *http.Request, action string, params url.Values) (int64, bool) {
+	id, err := pathID(r)
+	if err != nil {
+		writeAction(w, http.StatusBadRequest, "Invalid link", err.Error())
+		return 0, false
+	}
+
+	if err := h.links.Verify(action, id, params); err != nil {
+		writeAction(w, http.StatusForbidden, "Invalid link", err.Error())
+		return 0, false
+	}
+
+	return id, true
+}
+
+// writeActionError maps errors returned by the service to their HTTP status,
+// as a page for one-click links
+func writeActionError(w http.ResponseWriter, err error) {
+	var validationErr internal.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		writeAction(w, http.StatusBadRequest, "Cannot update reminder", err.Error())
+	case errors.Is(err, internal.ErrNotFound):
+		writeAction(w, http.StatusNotFound, "Reminder not found", err.Error())
+	case errors.Is(err, internal.ErrConflict):
+		writeAction(w, http.StatusConflict, "Reminder already answered", err.Error())
+	default:
+		writeAction(w, http.StatusInternalServerError, "Something went wrong", err.Error())
+	}
+}
+
+func writeAction(w http.ResponseWriter, status int, title, message string) {
+	tmpl, err := template.ParseFiles("templates/action.html")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	tmpl.Execute(w, map[string]string{"Title": title, "Message": message})
+}
+
+// writeConfirm renders the form posting the signed parameters of a one-click
+// link to its confirmation
+func writeConfirm(w http.ResponseWriter, id int64, action string, params url.Values, title, message, button string) {
+	tmpl, err := template.ParseFiles("templates/confirm.html")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	tmpl.Execute(w, map[string]any{
+		"Title":   title,
+		"Message": message,
+		"Button":  button,
+		"Action":  fmt.Sprintf("/schedules/%d/%s/confirm", id, action),
+		"Fields":  params,
+	})
+}
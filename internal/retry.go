@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy decides how failed schedules are retried. The delay before
+// attempt n+1 is BaseDelay * 2^(n-1), capped at MaxDelay, with up to Jitter
+// (a fraction between 0 and 1) of it randomly removed so retries of many
+// schedules do not hit the server at the same time.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	Jitter      float64
+}
+
+// Exhausted reports whether a schedule that failed attempts times must not be
+// retried anymore
+func (p RetryPolicy) Exhausted(attempts int) bool {
+	return attempts >= p.MaxAttempts
+}
+
+// Delay returns how long to wait after the given failed attempt, counted from 1
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	return p.delay(attempt, rand.Float64())
+}
+
+func (p RetryPolicy) delay(attempt int, random float64) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	delay := p.BaseDelay
+	for i := 1; i < attempt; i++ {
+		if (p.MaxDelay > 0 && delay >= p.MaxDelay) || delay > math.MaxInt64/2 {
+			break
+		}
+		delay *= 2
+	}
+
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	if p.Jitter > 0 {
+		jitter := min(p.Jitter, 1)
+		delay -= time.Duration(float64(delay) * jitter * random)
+	}
+
+	return delay
+}
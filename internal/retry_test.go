@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_delay(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts: 5,
+		BaseDelay:   30 * time.Second,
+		MaxDelay:    5 * time.Minute,
+	}
+	type args struct {
+		jitter  float64
+		attempt int
+		random  float64
+	}
+	tests := []struct {
+		name string
+		args args
+		want time.Duration
+	}{
+		{
+			name: "first attempt waits the base delay",
+			args: args{attempt: 1},
+			want: 30 * time.Second,
+		},
+		{
+			name: "attempt below one is treated as the first",
+			args: args{attempt: 0},
+			want: 30 * time.Second,
+		},
+		{
+			name: "delay doubles every attempt",
+			args: args{attempt: 3},
+			want: 2 * time.Minute,
+		},
+		{
+			name: "delay is capped at max delay",
+			args: args{attempt: 5},
+			want: 5 * time.Minute,
+		},
+		{
+			name: "large attempt does not overflow",
+			args: args{attempt: 200},
+			want: 5 * time.Minute,
+		},
+		{
+			name: "jitter removes a fraction of the delay",
+			args: args{jitter: 0.5, attempt: 2, random: 0.5},
+			want: 45 * time.Second,
+		},
+		{
+			name: "jitter above one is capped",
+			args: args{jitter: 3, attempt: 1, random: 0.5},
+			want: 15 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := policy
+			p.Jitter = tt.args.jitter
+			if got := p.delay(tt.args.attempt, tt.args.random); got != tt.want {
+				t.Errorf("RetryPolicy.delay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_Exhausted(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3}
+	if policy.Exhausted(2) {
+		t.Errorf("RetryPolicy.Exhausted(2) = true, want false")
+	}
+	if !policy.Exhausted(3) {
+		t.Errorf("RetryPolicy.Exhausted(3) = false, want true")
+	}
+}
@@ -0,0 +1,699 @@
+package internal
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type rruleFreq int
+
+const (
+	freqMinutely rruleFreq = iota
+	freqHourly
+	freqDaily
+	freqWeekly
+	freqMonthly
+	freqYearly
+)
+
+const (
+	rruleDateTimeUTC = "20060102T150405Z"
+	rruleDateTime    = "20060102T150405"
+	rruleDate        = "20060102"
+
+	// rruleSearchLimit stops the iteration of rules that never match again,
+	// e.g., "FREQ=MONTHLY;BYMONTHDAY=30;BYMONTH=2"
+	rruleSearchLimit = 50
+)
+
+var (
+	rruleFreqs = map[string]rruleFreq{
+		"MINUTELY": freqMinutely,
+		"HOURLY":   freqHourly,
+		"DAILY":    freqDaily,
+		"WEEKLY":   freqWeekly,
+		"MONTHLY":  freqMonthly,
+		"YEARLY":   freqYearly,
+	}
+
+	rruleWeekdays = map[string]time.Weekday{
+		"SU": time.Sunday,
+		"MO": time.Monday,
+		"TU": time.Tuesday,
+		"WE": time.Wednesday,
+		"TH": time.Thursday,
+		"FR": time.Friday,
+		"SA": time.Saturday,
+	}
+)
+
+type (
+	// rruleWeekday is a BYDAY entry, n is the optional ordinal, e.g., -1 in
+	// "-1FR" for the last Friday
+	rruleWeekday struct {
+		weekday time.Weekday
+		n       int
+	}
+
+	// rrule is a parsed RFC 5545 recurrence rule with its EXDATE exclusions.
+	// Occurrences are computed at wall clock times in the location of dtstart,
+	// see wallClock for wall clocks skipped or repeated by DST transitions.
+	rrule struct {
+		dtstart  time.Time
+		freq     rruleFreq
+		interval int
+		count    int
+		until    time.Time
+		wkst     time.Weekday
+
+		byMonth    []int
+		byMonthDay []int
+		byDay      []rruleWeekday
+		byHour     []int
+		byMinute   []int
+		bySecond   []int
+		bySetPos   []int
+
+		exdates []time.Time
+		// exdays holds EXDATE;VALUE=DATE entries, excluding whole days
+		exdays map[string]bool
+	}
+
+	// rruleIterator yields the occurrences of a rule in chronological order
+	rruleIterator struct {
+		rule     *rrule
+		period   int
+		pending  []time.Time
+		emitted  int
+		lastSeen time.Time
+		done     bool
+	}
+)
+
+// parseRRule parses a recurrence rule, either a bare "FREQ=...;..." value or
+// the lines of an iCalendar recurrence: one "RRULE:" line and any number of
+// "EXDATE" lines. dtstart is the start of the recurrence.
+func parseRRule(text string, dtstart time.Time) (*rrule, error) {
+	r := &rrule{
+		dtstart:  dtstart,
+		interval: 1,
+		wkst:     time.Monday,
+		exdays:   make(map[string]bool),
+	}
+
+	var hasRule bool
+	for _, line := range strings.FieldsFunc(text, func(c rune) bool { return c == '\n' || c == '\r' }) {
+		line = strings.TrimSpace(line)
+		name, value, found := strings.Cut(line, ":")
+		switch {
+		case line == "":
+		case !found && strings.Contains(strings.ToUpper(line), "FREQ="):
+			if hasRule {
+				return nil, fmt.Errorf("only one RRULE is supported")
+			}
+			if err := r.parseRule(line); err != nil {
+				return nil, err
+			}
+			hasRule = true
+		case strings.EqualFold(name, "RRULE"):
+			if hasRule {
+				return nil, fmt.Errorf("only one RRULE is supported")
+			}
+			if err := r.parseRule(value); err != nil {
+				return nil, err
+			}
+			hasRule = true
+		case strings.HasPrefix(strings.ToUpper(name), "EXDATE"):
+			if err := r.parseExdate(name, value); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(strings.ToUpper(name), "DTSTART"):
+			return nil, fmt.Errorf("DTSTART is not supported, the start time of the reminder is used")
+		default:
+			return nil, fmt.Errorf("unsupported recurrence line %q", line)
+		}
+	}
+
+	if !hasRule {
+		return nil, fmt.Errorf("missing RRULE")
+	}
+
+	return r, nil
+}
+
+func (r *rrule) parseRule(rule string) error {
+	var hasFreq bool
+	for _, part := range strings.Split(rule, ";") {
+		if part == "" {
+			continue
+		}
+
+		name, value, found := strings.Cut(part, "=")
+		if !found {
+			return fmt.Errorf("invalid rule part %q", part)
+		}
+
+		var err error
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			var ok bool
+			r.freq, ok = rruleFreqs[strings.ToUpper(value)]
+			if !ok {
+				return fmt.Errorf("unsupported FREQ %q", value)
+			}
+			hasFreq = true
+		case "INTERVAL":
+			r.interval, err = strconv.Atoi(value)
+			if err != nil || r.interval <= 0 {
+				return fmt.Errorf("invalid INTERVAL %q", value)
+			}
+		case "COUNT":
+			r.count, err = strconv.Atoi(value)
+			if err != nil || r.count <= 0 {
+				return fmt.Errorf("invalid COUNT %q", value)
+			}
+		case "UNTIL":
+			r.until, err = r.parseUntil(value)
+		case "WKST":
+			var ok bool
+			r.wkst, ok = rruleWeekdays[strings.ToUpper(value)]
+			if !ok {
+				return fmt.Errorf("invalid WKST %q", value)
+			}
+		case "BYMONTH":
+			r.byMonth, err = parseRRuleInts(name, value, 1, 12, false)
+		case "BYMONTHDAY":
+			r.byMonthDay, err = parseRRuleInts(name, value, 1, 31, true)
+		case "BYHOUR":
+			r.byHour, err = parseRRuleInts(name, value, 0, 23, false)
+		case "BYMINUTE":
+			r.byMinute, err = parseRRuleInts(name, value, 0, 59, false)
+		case "BYSECOND":
+			r.bySecond, err = parseRRuleInts(name, value, 0, 59, false)
+		case "BYSETPOS":
+			r.bySetPos, err = parseRRuleInts(name, value, 1, 366, true)
+		case "BYDAY":
+			r.byDay, err = parseRRuleWeekdays(value)
+		default:
+			return fmt.Errorf("unsupported rule part %q", name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if !hasFreq {
+		return fmt.Errorf("missing FREQ")
+	}
+
+	if r.count > 0 && !r.until.IsZero() {
+		return fmt.Errorf("COUNT and UNTIL cannot be combined")
+	}
+
+	for _, day := range r.byDay {
+		if day.n != 0 && r.freq != freqMonthly && r.freq != freqYearly {
+			return fmt.Errorf("BYDAY ordinals are only allowed with MONTHLY or YEARLY")
+		}
+	}
+
+	return nil
+}
+
+func (r *rrule) parseUntil(value string) (time.Time, error) {
+	if t, err := time.Parse(rruleDateTimeUTC, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(rruleDateTime, value, r.dtstart.Location()); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(rruleDate, value, r.dtstart.Location()); err == nil {
+		// a date UNTIL includes the whole day
+		return t.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid UNTIL %q", value)
+}
+
+// parseExdate parses "EXDATE[;TZID=...][;VALUE=DATE]:value[,value...]"
+func (r *rrule) parseExdate(name, value string) error {
+	loc := r.dtstart.Location()
+	var dateOnly bool
+	for _, param := range strings.Split(name, ";")[1:] {
+		key, val, _ := strings.Cut(param, "=")
+		switch strings.ToUpper(key) {
+		case "TZID":
+			var err error
+			loc, err = time.LoadLocation(val)
+			if err != nil {
+				return fmt.Errorf("invalid EXDATE TZID %q: %v", val, err)
+			}
+		case "VALUE":
+			dateOnly = strings.EqualFold(val, "DATE")
+		}
+	}
+
+	for _, v := range strings.Split(value, ",") {
+		v = strings.TrimSpace(v)
+		switch {
+		case dateOnly || len(v) == len(rruleDate):
+			d, err := time.ParseInLocation(rruleDate, v, loc)
+			if err != nil {
+				return fmt.Errorf("invalid EXDATE %q", v)
+			}
+			r.exdays[d.Format(time.DateOnly)] = true
+		case strings.HasSuffix(v, "Z"):
+			t, err := time.Parse(rruleDateTimeUTC, v)
+			if err != nil {
+				return fmt.Errorf("invalid EXDATE %q", v)
+			}
+			r.exdates = append(r.exdates, t)
+		default:
+			t, err := time.ParseInLocation(rruleDateTime, v, loc)
+			if err != nil {
+				return fmt.Errorf("invalid EXDATE %q", v)
+			}
+			r.exdates = append(r.exdates, t)
+		}
+	}
+
+	return nil
+}
+
+func parseRRuleInts(name, value string, min, max int, allowNegative bool) ([]int, error) {
+	var values []int
+	for _, s := range strings.Split(value, ",") {
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %q", name, s)
+		}
+
+		abs := v
+		if allowNegative && v < 0 {
+			abs = -v
+		}
+		if abs < min || abs > max {
+			return nil, fmt.Errorf("%s value %d out of range", name, v)
+		}
+
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func parseRRuleWeekdays(value string) ([]rruleWeekday, error) {
+	var days []rruleWeekday
+	for _, s := range strings.Split(value, ",") {
+		s = strings.ToUpper(strings.TrimSpace(s))
+		if len(s) < 2 {
+			return nil, fmt.Errorf("invalid BYDAY value %q", s)
+		}
+
+		weekday, ok := rruleWeekdays[s[len(s)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("invalid BYDAY value %q", s)
+		}
+
+		day := rruleWeekday{weekday: weekday}
+		if ordinal := s[:len(s)-2]; ordinal != "" {
+			n, err := strconv.Atoi(ordinal)
+			if err != nil || n == 0 || n > 53 || n < -53 {
+				return nil, fmt.Errorf("invalid BYDAY ordinal %q", s)
+			}
+			day.n = n
+		}
+
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// next returns the first occurrence strictly after t, or the zero time when
+// the rule has no more occurrences
+func (r *rrule) next(t time.Time) time.Time {
+	it := r.iterator(r.periodBefore(t))
+	for {
+		occurrence, ok := it.next()
+		if !ok {
+			return time.Time{}
+		}
+		if occurrence.After(t) {
+			return occurrence
+		}
+	}
+}
+
+// periodBefore returns the index of a period starting before t, so iteration
+// does not have to walk from dtstart. COUNT rules are always walked from
+// dtstart as every occurrence counts.
+func (r *rrule) periodBefore(t time.Time) int {
+	if r.count > 0 || !t.After(r.dtstart) {
+		return 0
+	}
+
+	var units int
+	switch r.freq {
+	case freqYearly:
+		units = t.Year() - r.dtstart.Year()
+	case freqMonthly:
+		units = (t.Year()-r.dtstart.Year())*12 + int(t.Month()) - int(r.dtstart.Month())
+	case freqWeekly:
+		units = int(t.Sub(r.dtstart).Hours() / (24 * 7))
+	case freqDaily:
+		units = int(t.Sub(r.dtstart).Hours() / 24)
+	case freqHourly:
+		units = int(t.Sub(r.dtstart).Hours())
+	case freqMinutely:
+		units = int(t.Sub(r.dtstart).Minutes())
+	}
+
+	// step one period back to stay clear of offset changes
+	return max(units/r.interval-1, 0)
+}
+
+func (r *rrule) iterator(period int) *rruleIterator {
+	return &rruleIterator{rule: r, period: period}
+}
+
+// next returns the next occurrence of the rule, skipping excluded dates
+func (it *rruleIterator) next() (time.Time, bool) {
+	for {
+		t, ok := it.nextGenerated()
+		if !ok {
+			return time.Time{}, false
+		}
+		if !it.rule.excluded(t) {
+			return t, true
+		}
+	}
+}
+
+// nextGenerated returns the next occurrence before exclusions are applied,
+// excluded occurrences still count towards COUNT
+func (it *rruleIterator) nextGenerated() (time.Time, bool) {
+	r := it.rule
+	for len(it.pending) == 0 {
+		if it.done {
+			return time.Time{}, false
+		}
+
+		start := r.periodStart(it.period)
+		reference := r.dtstart
+		if it.lastSeen.After(reference) {
+			reference = it.lastSeen
+		}
+		if start.After(reference.AddDate(rruleSearchLimit, 0, 0)) {
+			it.done = true
+			return time.Time{}, false
+		}
+
+		if skipTo := r.skip(start); !skipTo.IsZero() {
+			it.period = r.periodAt(skipTo, it.period+1)
+			continue
+		}
+
+		for _, t := range r.expand(start) {
+			if t.Before(r.dtstart) {
+				continue
+			}
+			it.pending = append(it.pending, t)
+		}
+		it.period++
+	}
+
+	t := it.pending[0]
+	it.pending = it.pending[1:]
+	it.lastSeen = t
+
+	if !r.until.IsZero() && t.After(r.until) {
+		it.done, it.pending = true, nil
+		return time.Time{}, false
+	}
+
+	it.emitted++
+	if r.count > 0 && it.emitted > r.count {
+		it.done, it.pending = true, nil
+		return time.Time{}, false
+	}
+
+	return t, true
+}
+
+func (r *rrule) excluded(t time.Time) bool {
+	if r.exdays[t.In(r.dtstart.Location()).Format(time.DateOnly)] {
+		return true
+	}
+	return slices.ContainsFunc(r.exdates, t.Equal)
+}
+
+// skip returns the earliest time the next matching period of a DAILY, HOURLY
+// or MINUTELY rule can start when the period starting at start cannot match
+// BYMONTH, the day rules or BYHOUR, and the zero time otherwise. Skipping the
+// month, day or hour at once, as dateutil does, keeps rules such as
+// "FREQ=MINUTELY;BYMONTH=2;BYMONTHDAY=29" from expanding every minute of the
+// years between their occurrences.
+func (r *rrule) skip(start time.Time) time.Time {
+	var next time.Time
+	switch {
+	case r.freq != freqDaily && r.freq != freqHourly && r.freq != freqMinutely:
+		return time.Time{}
+	case len(r.byMonth) > 0 && !slices.Contains(r.byMonth, int(start.Month())):
+		next = time.Date(start.Year(), start.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	case !r.matchDay(start):
+		next = time.Date(start.Year(), start.Month(), start.Day()+1, 0, 0, 0, 0, time.UTC)
+	case r.freq == freqMinutely && len(r.byHour) > 0 && !slices.Contains(r.byHour, start.Hour()):
+		next = time.Date(start.Year(), start.Month(), start.Day(), start.Hour()+1, 0, 0, 0, time.UTC)
+	default:
+		return time.Time{}
+	}
+
+	if t := wallClock(next, start.Location()); t.After(start) {
+		return t
+	}
+	return time.Time{}
+}
+
+// periodAt returns the index of the first period, from the nth, starting at
+// or after t
+func (r *rrule) periodAt(t time.Time, n int) int {
+	start := r.periodStart(0)
+
+	var units int
+	switch r.freq {
+	case freqDaily:
+		day := func(t time.Time) time.Time { return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC) }
+		units = int(day(t).Sub(day(start)).Hours() / 24)
+	case freqHourly:
+		units = int(t.Sub(start) / time.Hour)
+	case freqMinutely:
+		units = int(t.Sub(start) / time.Minute)
+	}
+
+	// step one period back, periodStart finds the exact one
+	n = max(units/r.interval-1, n)
+	for r.periodStart(n).Before(t) {
+		n++
+	}
+	return n
+}
+
+// periodStart returns the start of the nth period of the rule
+func (r *rrule) periodStart(n int) time.Time {
+	dt := r.dtstart
+	loc := dt.Location()
+	step := n * r.interval
+
+	switch r.freq {
+	case freqYearly:
+		return time.Date(dt.Year()+step, 1, 1, 0, 0, 0, 0, loc)
+	case freqMonthly:
+		return time.Date(dt.Year(), dt.Month()+time.Month(step), 1, 0, 0, 0, 0, loc)
+	case freqWeekly:
+		offset := (int(dt.Weekday()) - int(r.wkst) + 7) % 7
+		return time.Date(dt.Year(), dt.Month(), dt.Day()-offset+7*step, 0, 0, 0, 0, loc)
+	case freqDaily:
+		return time.Date(dt.Year(), dt.Month(), dt.Day()+step, 0, 0, 0, 0, loc)
+	case freqHourly:
+		// truncating the hour of the wall clock, not of the instant, keeps
+		// zones with half hour offsets aligned
+		hour := wallClock(time.Date(dt.Year(), dt.Month(), dt.Day(), dt.Hour(), 0, 0, 0, time.UTC), loc)
+		return hour.Add(time.Duration(step) * time.Hour)
+	default:
+		return dt.Truncate(time.Minute).Add(time.Duration(step) * time.Minute)
+	}
+}
+
+// expand returns the sorted occurrences of the period starting at start
+func (r *rrule) expand(start time.Time) []time.Time {
+	var days []time.Time
+	switch r.freq {
+	case freqYearly:
+		for d := start; d.Year() == start.Year(); d = d.AddDate(0, 0, 1) {
+			days = append(days, d)
+		}
+	case freqMonthly:
+		for d := start; d.Month() == start.Month(); d = d.AddDate(0, 0, 1) {
+			days = append(days, d)
+		}
+	case freqWeekly:
+		for i := 0; i < 7; i++ {
+			days = append(days, start.AddDate(0, 0, i))
+		}
+	default:
+		days = append(days, start)
+	}
+
+	var occurrences []time.Time
+	for _, day := range days {
+		if !r.matchDay(day) {
+			continue
+		}
+		for _, t := range r.times(day, start) {
+			occurrences = append(occurrences, t)
+		}
+	}
+
+	slices.SortFunc(occurrences, func(a, b time.Time) int { return a.Compare(b) })
+	occurrences = slices.CompactFunc(occurrences, time.Time.Equal)
+
+	if len(r.bySetPos) == 0 {
+		return occurrences
+	}
+
+	var selected []time.Time
+	for _, pos := range r.bySetPos {
+		i := pos - 1
+		if pos < 0 {
+			i = len(occurrences) + pos
+		}
+		if i >= 0 && i < len(occurrences) {
+			selected = append(selected, occurrences[i])
+		}
+	}
+	slices.SortFunc(selected, func(a, b time.Time) int { return a.Compare(b) })
+	return slices.CompactFunc(selected, time.Time.Equal)
+}
+
+func (r *rrule) matchDay(day time.Time) bool {
+	dt := r.dtstart
+
+	if len(r.byMonth) > 0 && !slices.Contains(r.byMonth, int(day.Month())) {
+		return false
+	}
+
+	if len(r.byMonthDay) > 0 {
+		daysInMonth := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		if !slices.ContainsFunc(r.byMonthDay, func(n int) bool {
+			return n == day.Day() || (n < 0 && daysInMonth+n+1 == day.Day())
+		}) {
+			return false
+		}
+	}
+
+	if len(r.byDay) > 0 && !slices.ContainsFunc(r.byDay, func(w rruleWeekday) bool { return r.matchWeekday(day, w) }) {
+		return false
+	}
+
+	// without BY* day rules the recurrence repeats on the day of dtstart
+	if len(r.byMonthDay) == 0 && len(r.byDay) == 0 {
+		switch r.freq {
+		case freqYearly:
+			if len(r.byMonth) == 0 && day.Month() != dt.Month() {
+				return false
+			}
+			return day.Day() == dt.Day()
+		case freqMonthly:
+			return day.Day() == dt.Day()
+		case freqWeekly:
+			return day.Weekday() == dt.Weekday()
+		}
+	}
+
+	return true
+}
+
+// matchWeekday matches a BYDAY entry, ordinals count within the month, or
+// within the year for YEARLY rules without BYMONTH
+func (r *rrule) matchWeekday(day time.Time, w rruleWeekday) bool {
+	if day.Weekday() != w.weekday {
+		return false
+	}
+	if w.n == 0 {
+		return true
+	}
+
+	var index, total int
+	if r.freq == freqYearly && len(r.byMonth) == 0 {
+		daysInYear := time.Date(day.Year(), 12, 31, 0, 0, 0, 0, time.UTC).YearDay()
+		index = (day.YearDay() - 1) / 7
+		total = (daysInYear - day.YearDay()) / 7
+	} else {
+		daysInMonth := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		index = (day.Day() - 1) / 7
+		total = (daysInMonth - day.Day()) / 7
+	}
+
+	if w.n > 0 {
+		return index == w.n-1
+	}
+	return total == -w.n-1
+}
+
+// times returns the occurrences within a matching day. HOURLY and MINUTELY
+// periods are instants, their occurrences are offsets from the period start
+// so an hour repeated by a DST transition runs twice.
+func (r *rrule) times(day, periodStart time.Time) []time.Time {
+	dt := r.dtstart
+
+	hours := r.byHour
+	minutes := r.byMinute
+	seconds := r.bySecond
+	if len(seconds) == 0 {
+		seconds = []int{dt.Second()}
+	}
+
+	var occurrences []time.Time
+	switch r.freq {
+	case freqHourly:
+		if len(hours) > 0 && !slices.Contains(hours, periodStart.Hour()) {
+			return nil
+		}
+		if len(minutes) == 0 {
+			minutes = []int{dt.Minute()}
+		}
+		for _, m := range minutes {
+			for _, s := range seconds {
+				occurrences = append(occurrences, periodStart.Add(time.Duration(m)*time.Minute+time.Duration(s)*time.Second))
+			}
+		}
+		return occurrences
+	case freqMinutely:
+		if len(hours) > 0 && !slices.Contains(hours, periodStart.Hour()) {
+			return nil
+		}
+		if len(minutes) > 0 && !slices.Contains(minutes, periodStart.Minute()) {
+			return nil
+		}
+		for _, s := range seconds {
+			occurrences = append(occurrences, periodStart.Add(time.Duration(s)*time.Second))
+		}
+		return occurrences
+	}
+
+	if len(hours) == 0 {
+		hours = []int{dt.Hour()}
+	}
+	if len(minutes) == 0 {
+		minutes = []int{dt.Minute()}
+	}
+	for _, h := range hours {
+		for _, m := range minutes {
+			for _, s := range seconds {
+				wall := time.Date(day.Year(), day.Month(), day.Day(), h, m, s, 0, time.UTC)
+				occurrences = append(occurrences, wallClock(wall, dt.Location()))
+			}
+		}
+	}
+	return occurrences
+}
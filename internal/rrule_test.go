@@ -0,0 +1,266 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseRRule(t *testing.T) {
+	dtstart := time.Date(2025, 7, 20, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		text    string
+		wantErr bool
+	}{
+		{name: "bare rule", text: "FREQ=MONTHLY;BYDAY=-1FR"},
+		{name: "rrule line", text: "RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;WKST=SU"},
+		{name: "with exdates", text: "RRULE:FREQ=DAILY;COUNT=10\nEXDATE:20250722T090000Z,20250723T090000Z\nEXDATE;VALUE=DATE:20250725"},
+		{name: "exdate with tzid", text: "RRULE:FREQ=DAILY\nEXDATE;TZID=Asia/Jakarta:20250722T160000"},
+		{name: "until date", text: "FREQ=DAILY;UNTIL=20250801"},
+		{name: "until utc", text: "FREQ=DAILY;UNTIL=20250801T090000Z"},
+		{name: "set position", text: "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1"},
+		{name: "empty", text: "", wantErr: true},
+		{name: "missing freq", text: "INTERVAL=2", wantErr: true},
+		{name: "unknown freq", text: "FREQ=FORTNIGHTLY", wantErr: true},
+		{name: "unsupported part", text: "FREQ=YEARLY;BYWEEKNO=20", wantErr: true},
+		{name: "zero interval", text: "FREQ=DAILY;INTERVAL=0", wantErr: true},
+		{name: "count and until", text: "FREQ=DAILY;COUNT=2;UNTIL=20250801", wantErr: true},
+		{name: "month day out of range", text: "FREQ=MONTHLY;BYMONTHDAY=32", wantErr: true},
+		{name: "invalid weekday", text: "FREQ=WEEKLY;BYDAY=XX", wantErr: true},
+		{name: "ordinal with weekly", text: "FREQ=WEEKLY;BYDAY=1MO", wantErr: true},
+		{name: "two rules", text: "RRULE:FREQ=DAILY\nRRULE:FREQ=WEEKLY", wantErr: true},
+		{name: "dtstart", text: "DTSTART:20250720T090000Z\nRRULE:FREQ=DAILY", wantErr: true},
+		{name: "invalid exdate", text: "RRULE:FREQ=DAILY\nEXDATE:tomorrow", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseRRule(tt.text, dtstart)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseRRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRRule_occurrences(t *testing.T) {
+	wib := time.FixedZone("WIB", 7*60*60)
+	at := func(year int, month time.Month, day, hour, min int) time.Time {
+		return time.Date(year, month, day, hour, min, 0, 0, wib)
+	}
+	tests := []struct {
+		name    string
+		text    string
+		dtstart time.Time
+		want    []time.Time
+	}{
+		{
+			name:    "last friday of every month",
+			text:    "FREQ=MONTHLY;BYDAY=-1FR",
+			dtstart: at(2025, 7, 1, 9, 0),
+			want:    []time.Time{at(2025, 7, 25, 9, 0), at(2025, 8, 29, 9, 0), at(2025, 9, 26, 9, 0), at(2025, 10, 31, 9, 0)},
+		},
+		{
+			name:    "every other week on monday and wednesday",
+			text:    "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE",
+			dtstart: at(2025, 7, 21, 8, 30),
+			want:    []time.Time{at(2025, 7, 21, 8, 30), at(2025, 7, 23, 8, 30), at(2025, 8, 4, 8, 30), at(2025, 8, 6, 8, 30)},
+		},
+		{
+			name:    "weekly defaults to the weekday of dtstart",
+			text:    "FREQ=WEEKLY",
+			dtstart: at(2025, 7, 20, 9, 0),
+			want:    []time.Time{at(2025, 7, 20, 9, 0), at(2025, 7, 27, 9, 0), at(2025, 8, 3, 9, 0)},
+		},
+		{
+			name:    "last weekday of the month",
+			text:    "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+			dtstart: at(2025, 7, 1, 17, 0),
+			want:    []time.Time{at(2025, 7, 31, 17, 0), at(2025, 8, 29, 17, 0), at(2025, 9, 30, 17, 0)},
+		},
+		{
+			name:    "monthly on the 31st skips short months",
+			text:    "FREQ=MONTHLY",
+			dtstart: at(2025, 1, 31, 9, 0),
+			want:    []time.Time{at(2025, 1, 31, 9, 0), at(2025, 3, 31, 9, 0), at(2025, 5, 31, 9, 0)},
+		},
+		{
+			name:    "second to last day of the month",
+			text:    "FREQ=MONTHLY;BYMONTHDAY=-2",
+			dtstart: at(2025, 1, 1, 9, 0),
+			want:    []time.Time{at(2025, 1, 30, 9, 0), at(2025, 2, 27, 9, 0), at(2025, 3, 30, 9, 0)},
+		},
+		{
+			name:    "yearly on the fourth thursday of november",
+			text:    "FREQ=YEARLY;BYMONTH=11;BYDAY=4TH",
+			dtstart: at(2025, 1, 1, 10, 0),
+			want:    []time.Time{at(2025, 11, 27, 10, 0), at(2026, 11, 26, 10, 0), at(2027, 11, 25, 10, 0)},
+		},
+		{
+			name:    "yearly on the 20th monday of the year",
+			text:    "FREQ=YEARLY;BYDAY=20MO",
+			dtstart: at(2025, 1, 1, 10, 0),
+			want:    []time.Time{at(2025, 5, 19, 10, 0), at(2026, 5, 18, 10, 0)},
+		},
+		{
+			name:    "daily at several hours",
+			text:    "FREQ=DAILY;BYHOUR=9,17;BYMINUTE=0",
+			dtstart: at(2025, 7, 20, 10, 38),
+			want:    []time.Time{at(2025, 7, 20, 17, 0), at(2025, 7, 21, 9, 0), at(2025, 7, 21, 17, 0)},
+		},
+		{
+			name:    "hourly within office hours",
+			text:    "FREQ=HOURLY;INTERVAL=4;BYHOUR=8,12,16",
+			dtstart: at(2025, 7, 20, 8, 0),
+			want:    []time.Time{at(2025, 7, 20, 8, 0), at(2025, 7, 20, 12, 0), at(2025, 7, 20, 16, 0), at(2025, 7, 21, 8, 0)},
+		},
+		{
+			name:    "count includes excluded dates",
+			text:    "RRULE:FREQ=DAILY;COUNT=4\nEXDATE:20250721T020000Z\nEXDATE;VALUE=DATE:20250723",
+			dtstart: at(2025, 7, 20, 9, 0),
+			want:    []time.Time{at(2025, 7, 20, 9, 0), at(2025, 7, 22, 9, 0)},
+		},
+		{
+			name:    "until is inclusive",
+			text:    "FREQ=DAILY;UNTIL=20250722T020000Z",
+			dtstart: at(2025, 7, 20, 9, 0),
+			want:    []time.Time{at(2025, 7, 20, 9, 0), at(2025, 7, 21, 9, 0), at(2025, 7, 22, 9, 0)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := parseRRule(tt.text, tt.dtstart)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []time.Time
+			it := r.iterator(0)
+			for len(got) < len(tt.want) {
+				occurrence, ok := it.next()
+				if !ok {
+					break
+				}
+				got = append(got, occurrence)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rruleIterator.next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRRule_next(t *testing.T) {
+	dtstart := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		text string
+		t    time.Time
+		want time.Time
+	}{
+		{
+			name: "strictly after the given time",
+			text: "FREQ=MONTHLY;BYDAY=-1FR",
+			t:    time.Date(2025, 7, 25, 9, 0, 0, 0, time.UTC),
+			want: time.Date(2025, 8, 29, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "far from dtstart",
+			text: "FREQ=DAILY;INTERVAL=3",
+			t:    time.Date(2030, 6, 1, 12, 0, 0, 0, time.UTC),
+			want: time.Date(2030, 6, 4, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "before dtstart",
+			text: "FREQ=DAILY",
+			t:    time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+			want: dtstart,
+		},
+		{
+			name: "after the last occurrence",
+			text: "FREQ=WEEKLY;COUNT=3",
+			t:    time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC),
+			want: time.Time{},
+		},
+		{
+			name: "minutely rule a year later",
+			text: "FREQ=MINUTELY;BYMONTH=1;BYMONTHDAY=1;BYHOUR=9;BYMINUTE=0,1",
+			t:    time.Date(2025, 1, 1, 9, 1, 0, 0, time.UTC),
+			want: time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "excluded occurrence is skipped",
+			text: "RRULE:FREQ=WEEKLY\nEXDATE:20250108T090000Z",
+			t:    dtstart,
+			want: time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := parseRRule(tt.text, dtstart)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := r.next(tt.t); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rrule.next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRRule_skip(t *testing.T) {
+	dtstart := time.Date(2028, 2, 29, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		text string
+		t    time.Time
+		want time.Time
+	}{
+		{
+			name: "minutely on the next leap day",
+			text: "FREQ=MINUTELY;BYMONTH=2;BYMONTHDAY=29;BYHOUR=9;BYMINUTE=0",
+			t:    dtstart,
+			want: time.Date(2032, 2, 29, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "minutely with an interval keeps its step",
+			text: "FREQ=MINUTELY;INTERVAL=7;BYMONTHDAY=1;BYHOUR=10",
+			t:    dtstart,
+			// 10:00 on March 1st is 1500 minutes after dtstart, the first
+			// multiple of 7 after it is 1505
+			want: time.Date(2028, 3, 1, 10, 5, 0, 0, time.UTC),
+		},
+		{
+			name: "hourly on a weekday",
+			text: "FREQ=HOURLY;INTERVAL=5;BYDAY=SU",
+			t:    dtstart,
+			// Sunday March 5th starts 111 hours after dtstart, the first
+			// multiple of 5 after it is 115
+			want: time.Date(2028, 3, 5, 4, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "daily in a month",
+			text: "FREQ=DAILY;INTERVAL=2;BYMONTH=4",
+			t:    dtstart,
+			// April 1st is 32 days after dtstart
+			want: time.Date(2028, 4, 1, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "impossible date",
+			text: "FREQ=MINUTELY;BYMONTH=2;BYMONTHDAY=30",
+			t:    dtstart,
+			want: time.Time{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := parseRRule(tt.text, dtstart)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := r.next(tt.t); !got.Equal(tt.want) {
+				t.Errorf("rrule.next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
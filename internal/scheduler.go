@@ -1,6 +1,9 @@
 package internal
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 const (
 	StatusCanceled ActionStatus = iota - 1
@@ -8,29 +11,102 @@ const (
 	StatusSending
 	StatusFailed
 	StatusSuccess
+	// StatusDead is a schedule that failed and exhausted its retry attempts
+	StatusDead
+	// StatusMissed is a schedule dropped by the misfire policy of its reminder
+	StatusMissed
+	// StatusQuiet is a schedule held back from every recipient by quiet
+	// hours, its deferred schedules deliver it later
+	StatusQuiet
 )
 
 type (
 	ActionStatus int8
 
 	Schedule struct {
-		ID       int64        `json:"id"`
-		TaskID   int64        `json:"task_id"`
-		Status   ActionStatus `json:"action_status"`
-		NotifyAt time.Time    `json:"notify_at"`
-		DoneAt   time.Time    `json:"done_at"`
-		IsDone   bool         `json:"is_done"` // indicates if the scheduler has completed its action callback via email or API calls
+		ID         int64        `json:"id"`
+		TaskID     int64        `json:"task_id"`
+		ReminderID int64        `json:"reminder_id"`
+		Status     ActionStatus `json:"action_status"`
+		NotifyAt   time.Time    `json:"notify_at"`
+		DoneAt     time.Time    `json:"done_at"`
+		IsDone     bool         `json:"is_done"` // indicates if the scheduler has completed its action callback via email or API calls
+		// OccurrenceAt is the occurrence of the reminder the schedule fires,
+		// NotifyAt differs from it when an override exception moved the run.
+		// The next schedule of the reminder follows OccurrenceAt.
+		OccurrenceAt time.Time `json:"occurrence_at"`
+		// ResponseStatus is the HTTP status returned by the webhook of the
+		// schedule, zero for other channels
+		ResponseStatus int `json:"response_status"`
+		// Attempts counts the failed deliveries of the schedule, a failed
+		// schedule is retried at RetryAt until its retry policy is exhausted.
+		// A sending schedule is taken over at RetryAt by another dispatcher.
+		Attempts  int       `json:"attempts"`
+		LastError string    `json:"last_error"`
+		RetryAt   time.Time `json:"retry_at"`
+		// Misfire is the misfire policy applied when the schedule was due long
+		// before the dispatcher picked it up, empty for schedules fired on
+		// time. Missed counts the occurrences the policy passed over, a
+		// schedule that fired once for several occurrences moves its
+		// OccurrenceAt to the latest of them.
+		Misfire string `json:"misfire"`
+		Missed  int    `json:"missed"`
+		// Kind is ScheduleOccurrence, ScheduleSnooze, ScheduleEscalation or
+		// ScheduleDeferred. A snooze fires the schedule of ParentID again, an
+		// escalation notifies the Step-th step of the escalation policy while
+		// the schedule of ParentID stays unacknowledged.
+		Kind     string `json:"kind"`
+		ParentID int64  `json:"parent_id"`
+		Step     int    `json:"step"`
+		// Recipients are the recipients the schedule notifies instead of the
+		// ones of its reminder, set when quiet hours split its delivery
+		Recipients []string `json:"recipients"`
+		// Response is how the recipient responded to the fired schedule,
+		// ResponseAcknowledged or ResponseSnoozed, empty until then
+		Response    string    `json:"response"`
+		RespondedAt time.Time `json:"responded_at"`
+
+		// Reminder is the reminder the schedule was created from, attached by
+		// the dispatcher before the schedule is handed to a notifier
+		Reminder *Reminder `json:"-"`
 
 		CreatedAt time.Time `json:"created_at"`
 		UpdatedAt time.Time `json:"updated_at"`
 	}
 )
 
-func NewSchedule(taskID int64, notifyAt time.Time) *Schedule {
+func NewSchedule(taskID, reminderID int64, notifyAt time.Time) *Schedule {
 	return &Schedule{
-		TaskID:   taskID,
-		Status:   StatusCreated,
-		NotifyAt: notifyAt,
-		IsDone:   false,
+		TaskID:     taskID,
+		ReminderID: reminderID,
+		Status:     StatusCreated,
+		NotifyAt:   notifyAt,
+		// a schedule fires its occurrence unless an override moved it
+		OccurrenceAt: notifyAt,
+		Kind:         ScheduleOccurrence,
+		IsDone:       false,
+	}
+}
+
+func (s ActionStatus) String() string {
+	switch s {
+	case StatusCanceled:
+		return "canceled"
+	case StatusCreated:
+		return "created"
+	case StatusSending:
+		return "sending"
+	case StatusFailed:
+		return "failed"
+	case StatusSuccess:
+		return "success"
+	case StatusDead:
+		return "dead"
+	case StatusMissed:
+		return "missed"
+	case StatusQuiet:
+		return "quiet"
+	default:
+		return fmt.Sprintf("ActionStatus(%d)", int8(s))
 	}
 }
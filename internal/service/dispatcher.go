@@ -0,0 +1,468 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/elangreza/scheduler/internal"
+	"github.com/elangreza/scheduler/internal/notifier"
+)
+
+const (
+	dispatchBatchSize = 100
+	// dispatchLease is how long a claimed schedule is left to its dispatcher
+	// before another one takes it over, in case the first one crashed or
+	// failed storing its outcome
+	dispatchLease = 5 * time.Minute
+)
+
+type (
+	dispatcherTaskRepo interface {
+		GetTask(ctx context.Context, id int64) (*internal.Task, error)
+	}
+
+	dispatcherReminderRepo interface {
+		GetReminder(ctx context.Context, id int64) (*internal.Reminder, error)
+		ListIdleReminders(ctx context.Context) ([]internal.Reminder, error)
+	}
+
+	scheduleRepo interface {
+		CreateSchedule(ctx context.Context, schedule internal.Schedule) (int64, error)
+		GetLastSchedule(ctx context.Context, reminderID int64) (*internal.Schedule, error)
+		ListDueSchedules(ctx context.Context, now time.Time, limit int) ([]internal.Schedule, error)
+		ClaimSchedule(ctx context.Context, id int64, now, leaseUntil time.Time) (bool, error)
+		FinishSchedule(ctx context.Context, schedule internal.Schedule) error
+		MisfireSchedule(ctx context.Context, schedule internal.Schedule) error
+		RetrySchedule(ctx context.Context, schedule internal.Schedule) error
+		EscalationResponded(ctx context.Context, parentID int64) (bool, error)
+		QuietSchedule(ctx context.Context, schedule internal.Schedule, deferred []internal.Schedule) error
+		OutboxSchedule(ctx context.Context, schedule internal.Schedule, entries []internal.OutboxEntry) error
+	}
+
+	quietHoursRepo interface {
+		ListQuietHours(ctx context.Context) ([]internal.QuietHours, error)
+	}
+
+	notifiers interface {
+		Get(channel string) (notifier.Notifier, error)
+	}
+
+	// relay delivers the outbox entries written by the dispatcher
+	relay interface {
+		Notify()
+	}
+
+	// Dispatcher materializes the next Schedule of every Reminder and fires
+	// the schedules once they are due.
+	Dispatcher struct {
+		taskRepo     dispatcherTaskRepo
+		reminderRepo dispatcherReminderRepo
+		scheduleRepo scheduleRepo
+		notifiers    notifiers
+		calendars    calendars
+		retryPolicy  internal.RetryPolicy
+		interval     time.Duration
+		// quietHoursRepo holds the quiet hours of recipients, quietHours are
+		// the global ones, nil for none
+		quietHoursRepo quietHoursRepo
+		quietHours     *internal.QuietHours
+		// misfireThreshold is the lag after which a due schedule is handled
+		// by the misfire policy of its reminder
+		misfireThreshold time.Duration
+		clock            internal.Clock
+		// relay delivers the emails of fired schedules from the outbox, nil
+		// to send them when the schedules fire
+		relay relay
+	}
+)
+
+func NewDispatcher(taskRepo dispatcherTaskRepo, reminderRepo dispatcherReminderRepo, scheduleRepo scheduleRepo, quietHoursRepo quietHoursRepo, notifiers notifiers, relay relay, calendars calendars, retryPolicy internal.RetryPolicy, quietHours *internal.QuietHours, interval, misfireThreshold time.Duration, clock internal.Clock) *Dispatcher {
+	return &Dispatcher{
+		taskRepo:         taskRepo,
+		reminderRepo:     reminderRepo,
+		scheduleRepo:     scheduleRepo,
+		quietHoursRepo:   quietHoursRepo,
+		notifiers:        notifiers,
+		relay:            relay,
+		calendars:        calendars,
+		retryPolicy:      retryPolicy,
+		quietHours:       quietHours,
+		interval:         interval,
+		misfireThreshold: misfireThreshold,
+		clock:            clock,
+	}
+}
+
+// Run ticks the dispatcher every interval until ctx is canceled
+func (d *Dispatcher) Run(ctx context.Context) {
+	timer := d.clock.NewTimer(d.interval)
+	defer timer.Stop()
+
+	for {
+		if err := d.Tick(ctx, d.clock.Now()); err != nil {
+			log.Println("dispatcher:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C():
+			timer.Reset(d.interval)
+		}
+	}
+}
+
+// Tick enqueues the next schedule of idle reminders and fires the due ones
+func (d *Dispatcher) Tick(ctx context.Context, now time.Time) error {
+	if err := d.materialize(ctx); err != nil {
+		return err
+	}
+
+	return d.dispatch(ctx, now)
+}
+
+func (d *Dispatcher) materialize(ctx context.Context) error {
+	reminders, err := d.reminderRepo.ListIdleReminders(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, reminder := range reminders {
+		if err := d.enqueueNext(ctx, reminder); err != nil {
+			log.Printf("dispatcher: enqueue reminder %d: %v", reminder.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (d *Dispatcher) enqueueNext(ctx context.Context, reminder internal.Reminder) error {
+	if err := attachCalendar(d.calendars, &reminder); err != nil {
+		return err
+	}
+
+	// the first occurrence of a reminder is derived from its start time, the
+	// following occurrences are derived from the last schedule
+	occurrence := reminder.FirstRunAt()
+	last, err := d.scheduleRepo.GetLastSchedule(ctx, reminder.ID)
+	switch {
+	case errors.Is(err, internal.ErrNotFound):
+		if occurrence.IsZero() {
+			return nil
+		}
+	case err != nil:
+		return err
+	default:
+		occurrence = reminder.GetNextRunAt(last.OccurrenceAt)
+		if occurrence.IsZero() || !occurrence.After(last.OccurrenceAt) {
+			// the reminder has no more runs
+			return nil
+		}
+	}
+
+	schedule := internal.NewSchedule(reminder.TaskID, reminder.ID, reminder.RunAt(occurrence))
+	schedule.OccurrenceAt = occurrence
+	_, err = d.scheduleRepo.CreateSchedule(ctx, *schedule)
+	return err
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context, now time.Time) error {
+	schedules, err := d.scheduleRepo.ListDueSchedules(ctx, now, dispatchBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, schedule := range schedules {
+		if err := d.dispatchSchedule(ctx, schedule, now); err != nil {
+			log.Printf("dispatcher: dispatch schedule %d: %v", schedule.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// dispatchSchedule claims a due schedule and delivers it, unless its misfire
+// policy, escalation or quiet hours finish it first. A schedule failing here
+// keeps its lease and is dispatched again once the lease expires.
+func (d *Dispatcher) dispatchSchedule(ctx context.Context, schedule internal.Schedule, now time.Time) error {
+	claimed, err := d.scheduleRepo.ClaimSchedule(ctx, schedule.ID, now, now.Add(dispatchLease))
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return nil
+	}
+
+	if schedule.Misfired(now, d.misfireThreshold) {
+		fire, err := d.misfire(ctx, &schedule, now)
+		if err != nil {
+			return err
+		}
+		if !fire {
+			return nil
+		}
+	}
+
+	if schedule.Step > 0 {
+		fire, err := d.escalation(ctx, &schedule, now)
+		if err != nil {
+			return err
+		}
+		if !fire {
+			return nil
+		}
+	}
+
+	fire, err := d.quiet(ctx, &schedule, now)
+	if err != nil {
+		return err
+	}
+	if !fire {
+		return nil
+	}
+
+	return d.deliver(ctx, schedule)
+}
+
+// misfire applies the misfire policy of its reminder to a claimed schedule
+// that was due long before now, and reports whether the schedule still fires.
+// The decision is stored on the schedule before it is delivered.
+func (d *Dispatcher) misfire(ctx context.Context, schedule *internal.Schedule, now time.Time) (bool, error) {
+	reminder, err := d.reminderRepo.GetReminder(ctx, schedule.ReminderID)
+	if err != nil {
+		return false, err
+	}
+	if err := attachCalendar(d.calendars, reminder); err != nil {
+		return false, err
+	}
+
+	// the next schedule follows the occurrence of this one, moving it to the
+	// latest missed occurrence passes over the missed ones
+	missed, latest := reminder.MissedSince(schedule.OccurrenceAt, now)
+	switch reminder.MisfirePolicy {
+	case internal.MisfireFireOnce:
+		schedule.Misfire = internal.MisfireFireOnce
+		schedule.Missed = missed
+		if !latest.IsZero() {
+			schedule.OccurrenceAt = latest
+		}
+	case internal.MisfireSkip:
+		schedule.Misfire = internal.MisfireSkip
+		schedule.Missed = missed + 1
+		if !latest.IsZero() {
+			schedule.OccurrenceAt = latest
+		}
+	default:
+		schedule.Misfire = internal.MisfireFireAll
+	}
+
+	log.Printf("dispatcher: schedule %d of reminder %d due at %s missed, %s passes over %d runs", schedule.ID, schedule.ReminderID, schedule.NotifyAt.Format(time.RFC3339), schedule.Misfire, schedule.Missed)
+	if err := d.scheduleRepo.MisfireSchedule(ctx, *schedule); err != nil {
+		return false, err
+	}
+
+	if schedule.Misfire != internal.MisfireSkip {
+		return true, nil
+	}
+
+	schedule.Status = internal.StatusMissed
+	schedule.DoneAt = now
+	return false, d.scheduleRepo.FinishSchedule(ctx, *schedule)
+}
+
+// quiet holds a claimed schedule back from the recipients in quiet hours, and
+// reports whether it is still delivered now, to the recipients left. A
+// schedule held back from every recipient is finished as quiet.
+func (d *Dispatcher) quiet(ctx context.Context, schedule *internal.Schedule, now time.Time) (bool, error) {
+	reminder, err := d.notifiedReminder(ctx, *schedule)
+	if err != nil {
+		// left to fail and be retried by the delivery
+		return true, nil
+	}
+
+	recipients, err := d.quietHoursRepo.ListQuietHours(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	delivery := schedule.Quiet(reminder, internal.NewQuietWindows(d.quietHours, recipients), now)
+	if !delivery.Held() {
+		return true, nil
+	}
+
+	deferred := make([]internal.Schedule, 0, len(delivery.Deferred))
+	for _, schedule := range delivery.Deferred {
+		deferred = append(deferred, *schedule)
+	}
+
+	log.Printf("dispatcher: schedule %d held back by quiet hours, %d recipients notified now, %d deferred schedules, %d recipients dropped", schedule.ID, len(delivery.Recipients), len(delivery.Deferred), len(delivery.Dropped))
+	schedule.Recipients = delivery.Recipients
+	if err := d.scheduleRepo.QuietSchedule(ctx, *schedule, deferred); err != nil {
+		return false, err
+	}
+
+	if delivery.Send {
+		return true, nil
+	}
+
+	// the schedule escalates as if it was delivered, the recipients of the
+	// escalation have quiet hours of their own
+	schedule.Reminder = reminder
+	schedule.Status = internal.StatusQuiet
+	schedule.DoneAt = now
+	if err := d.scheduleRepo.FinishSchedule(ctx, *schedule); err != nil {
+		return false, err
+	}
+	return false, d.escalate(ctx, *schedule, now)
+}
+
+// escalation checks a claimed escalation schedule before it fires, and
+// reports whether it still fires. Escalations of a schedule its recipients
+// responded to, or whose step was removed from the policy, are canceled.
+func (d *Dispatcher) escalation(ctx context.Context, schedule *internal.Schedule, now time.Time) (bool, error) {
+	responded, err := d.scheduleRepo.EscalationResponded(ctx, schedule.ParentID)
+	if err != nil {
+		return false, err
+	}
+
+	if !responded {
+		reminder, err := d.reminderRepo.GetReminder(ctx, schedule.ReminderID)
+		if err != nil {
+			return false, err
+		}
+		if _, err := reminder.Escalated(schedule.Step); err == nil {
+			return true, nil
+		}
+	}
+
+	log.Printf("dispatcher: escalation %d of schedule %d canceled, step %d", schedule.ID, schedule.ParentID, schedule.Step)
+	schedule.Status = internal.StatusCanceled
+	schedule.DoneAt = now
+	return false, d.scheduleRepo.FinishSchedule(ctx, *schedule)
+}
+
+// deliver fires a claimed schedule and stores the outcome. Failed deliveries
+// are retried with backoff until the retry policy is exhausted, permanent
+// failures are not retried. A delivered or dead schedule enqueues the next
+// step of its escalation chain. Emails written to the outbox are delivered by
+// the relay, the schedule is delivered once they are written and dead if the
+// relay gives up on one of them.
+func (d *Dispatcher) deliver(ctx context.Context, schedule internal.Schedule) error {
+	entries, err := d.fire(ctx, &schedule)
+	now := d.clock.Now()
+	if err == nil {
+		schedule.Status = internal.StatusSuccess
+		schedule.DoneAt = now
+		if len(entries) == 0 {
+			if err := d.scheduleRepo.FinishSchedule(ctx, schedule); err != nil {
+				return err
+			}
+			return d.escalate(ctx, schedule, now)
+		}
+
+		if err := d.scheduleRepo.OutboxSchedule(ctx, schedule, entries); err != nil {
+			return err
+		}
+		d.relay.Notify()
+		return d.escalate(ctx, schedule, now)
+	}
+
+	schedule.Attempts++
+	schedule.LastError = err.Error()
+	var permanent internal.PermanentError
+	if errors.As(err, &permanent) || d.retryPolicy.Exhausted(schedule.Attempts) {
+		log.Printf("dispatcher: schedule %d failed permanently after %d attempts: %v", schedule.ID, schedule.Attempts, err)
+		schedule.Status = internal.StatusDead
+		schedule.DoneAt = now
+		if err := d.scheduleRepo.FinishSchedule(ctx, schedule); err != nil {
+			return err
+		}
+		return d.escalate(ctx, schedule, now)
+	}
+
+	schedule.RetryAt = now.Add(d.retryPolicy.Delay(schedule.Attempts))
+	log.Printf("dispatcher: schedule %d failed, attempt %d retried at %s: %v", schedule.ID, schedule.Attempts, schedule.RetryAt.Format(time.RFC3339), err)
+	return d.scheduleRepo.RetrySchedule(ctx, schedule)
+}
+
+// escalate enqueues the escalation following a finished schedule, if the
+// escalation policy of its reminder has one
+func (d *Dispatcher) escalate(ctx context.Context, schedule internal.Schedule, now time.Time) error {
+	// the reminder is attached once the schedule fired
+	if schedule.Reminder == nil {
+		return nil
+	}
+
+	escalation := schedule.Escalate(schedule.Reminder, now)
+	if escalation == nil {
+		return nil
+	}
+
+	_, err := d.scheduleRepo.CreateSchedule(ctx, *escalation)
+	return err
+}
+
+// notifiedReminder returns the reminder of a schedule as it notifies the
+// schedule, escalated for the step of an escalation
+func (d *Dispatcher) notifiedReminder(ctx context.Context, schedule internal.Schedule) (*internal.Reminder, error) {
+	reminder, err := d.reminderRepo.GetReminder(ctx, schedule.ReminderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if schedule.Step > 0 {
+		return reminder.Escalated(schedule.Step)
+	}
+	return reminder, nil
+}
+
+// fire hands the schedule to the notifier of its reminder channel, or of its
+// escalation step, notifying the recipients of the schedule when it has its
+// own. With a relay, the emails of a composing notifier are returned as
+// outbox entries instead of being sent. Other notifiers, such as webhooks
+// and logs, still send inline: a schedule taken over after its lease expired
+// sends them again.
+func (d *Dispatcher) fire(ctx context.Context, schedule *internal.Schedule) ([]internal.OutboxEntry, error) {
+	reminder, err := d.notifiedReminder(ctx, *schedule)
+	if err != nil {
+		return nil, err
+	}
+	if len(schedule.Recipients) > 0 {
+		reminder.Recipients = schedule.Recipients
+	}
+	schedule.Reminder = reminder
+
+	task, err := d.taskRepo.GetTask(ctx, schedule.TaskID)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := d.notifiers.Get(reminder.DeliveryChannel())
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("dispatcher: schedule %d of task %d fired through %s, notify at %s", schedule.ID, schedule.TaskID, reminder.DeliveryChannel(), schedule.NotifyAt.Format(time.RFC3339))
+	composer, ok := n.(notifier.Composer)
+	if d.relay == nil || !ok {
+		return nil, n.Send(ctx, schedule, *task)
+	}
+
+	messages, err := composer.Compose(ctx, schedule, *task)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]internal.OutboxEntry, 0, len(messages))
+	for i, msg := range messages {
+		payload, err := json.Marshal(msg)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *internal.NewOutboxEntry(schedule.ID, i, payload, d.clock.Now()))
+	}
+	return entries, nil
+}
@@ -0,0 +1,668 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/elangreza/scheduler/internal"
+	"github.com/elangreza/scheduler/internal/emailtemplate"
+	"github.com/elangreza/scheduler/internal/mailer"
+	"github.com/elangreza/scheduler/internal/notifier"
+	"github.com/elangreza/scheduler/internal/sqliterepo"
+)
+
+const (
+	testInterval         = time.Minute
+	testMisfireThreshold = 2 * time.Minute
+)
+
+// outbox is a mailer recording the emails it sends
+type outbox struct {
+	mu     sync.Mutex
+	emails []string
+	sent   chan string
+}
+
+func (o *outbox) Send(msg mailer.Message) error {
+	o.mu.Lock()
+	o.emails = append(o.emails, strings.Join(msg.To, ","))
+	o.mu.Unlock()
+
+	select {
+	case o.sent <- msg.Subject:
+	default:
+	}
+	return nil
+}
+
+func (o *outbox) count() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return len(o.emails)
+}
+
+// recipients returns the recipients of every sent email, in sending order
+func (o *outbox) recipients() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return append([]string{}, o.emails...)
+}
+
+type dispatcherTest struct {
+	ctx        context.Context
+	db         *sql.DB
+	clock      *internal.FakeClock
+	outbox     *outbox
+	service    *TaskService
+	dispatcher *Dispatcher
+	relay      *Relay
+}
+
+func newDispatcherTest(t *testing.T, now time.Time) *dispatcherTest {
+	t.Helper()
+
+	db, err := sqliterepo.NewSql(filepath.Join(t.TempDir(), "scheduler.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := sqliterepo.Migrate(db, "../../migrations"); err != nil {
+		t.Fatal(err)
+	}
+
+	taskRepo := sqliterepo.NewTaskRepository(db)
+	reminderRepo := sqliterepo.NewReminderRepository(db)
+	scheduleRepo := sqliterepo.NewScheduleRepository(db)
+	escalationRepo := sqliterepo.NewEscalationRepository(db)
+	quietHoursRepo := sqliterepo.NewQuietHoursRepository(db)
+	calendars := noCalendars{}
+	clock := internal.NewFakeClock(now)
+
+	outbox := &outbox{sent: make(chan string, 100)}
+	notifiers := notifier.NewRegistry()
+	notifiers.Register(internal.ChannelEmail, notifier.NewEmail(outbox, nil, nil, nil, false))
+	notifiers.Register(internal.ChannelWebhook, notifier.NewWebhook(nil, clock))
+
+	retryPolicy := internal.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour}
+	relay := NewRelay(sqliterepo.NewOutboxRepository(db), outbox, retryPolicy, testInterval, clock)
+	test := &dispatcherTest{
+		ctx:        context.Background(),
+		db:         db,
+		clock:      clock,
+		outbox:     outbox,
+		service:    NewTaskService(taskRepo, reminderRepo, scheduleRepo, escalationRepo, quietHoursRepo, calendars, emailtemplate.NewRegistry(), clock),
+		dispatcher: NewDispatcher(taskRepo, reminderRepo, scheduleRepo, quietHoursRepo, notifiers, relay, calendars, retryPolicy, nil, testInterval, testMisfireThreshold, clock),
+		relay:      relay,
+	}
+
+	if err := test.service.CreateTask(test.ctx, internal.CreateTaskParams{Name: "water the plants", Description: "every pot"}); err != nil {
+		t.Fatal(err)
+	}
+
+	return test
+}
+
+// advance moves the clock forward by d, ticking the dispatcher and then the
+// relay every interval
+func (d *dispatcherTest) advance(t *testing.T, duration time.Duration) {
+	t.Helper()
+
+	for elapsed := time.Duration(0); elapsed < duration; elapsed += testInterval {
+		d.clock.Advance(testInterval)
+		if err := d.dispatcher.Tick(d.ctx, d.clock.Now()); err != nil {
+			t.Fatal(err)
+		}
+		if err := d.relay.Tick(d.ctx, d.clock.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+type noCalendars struct{}
+
+func (noCalendars) Get(name string) (*internal.Calendar, error) {
+	return internal.NewCalendar(name, nil, nil), nil
+}
+
+func (noCalendars) List() []internal.Calendar {
+	return nil
+}
+
+func TestDispatcher_Tick(t *testing.T) {
+	now := time.Date(2025, 7, 20, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		reminder internal.CreateReminderParams
+		downtime time.Duration
+		advance  time.Duration
+		wantSent int
+	}{
+		{
+			name: "every 20 minutes",
+			reminder: internal.CreateReminderParams{
+				StartTime:    now.Add(20 * time.Minute).Format(time.RFC3339),
+				RepeatHourly: "20m",
+			},
+			advance:  2 * time.Hour,
+			wantSent: 6,
+		},
+		{
+			name: "one time",
+			reminder: internal.CreateReminderParams{
+				StartTime: now.Add(30 * time.Minute).Format(time.RFC3339),
+			},
+			advance:  2 * time.Hour,
+			wantSent: 1,
+		},
+		{
+			name: "max occurrences",
+			reminder: internal.CreateReminderParams{
+				StartTime:      now.Add(20 * time.Minute).Format(time.RFC3339),
+				RepeatHourly:   "20m",
+				MaxOccurrences: 3,
+			},
+			advance:  2 * time.Hour,
+			wantSent: 3,
+		},
+		{
+			name: "fire all after downtime",
+			reminder: internal.CreateReminderParams{
+				StartTime:     now.Add(20 * time.Minute).Format(time.RFC3339),
+				RepeatHourly:  "20m",
+				MisfirePolicy: internal.MisfireFireAll,
+			},
+			downtime: 2 * time.Hour,
+			advance:  10 * time.Minute,
+			wantSent: 6,
+		},
+		{
+			name: "fire once after downtime",
+			reminder: internal.CreateReminderParams{
+				StartTime:     now.Add(20 * time.Minute).Format(time.RFC3339),
+				RepeatHourly:  "20m",
+				MisfirePolicy: internal.MisfireFireOnce,
+			},
+			downtime: 2 * time.Hour,
+			advance:  10 * time.Minute,
+			wantSent: 1,
+		},
+		{
+			name: "skip after downtime",
+			reminder: internal.CreateReminderParams{
+				StartTime:     now.Add(20 * time.Minute).Format(time.RFC3339),
+				RepeatHourly:  "20m",
+				MisfirePolicy: internal.MisfireSkip,
+			},
+			downtime: 2 * time.Hour,
+			advance:  30 * time.Minute,
+			wantSent: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := newDispatcherTest(t, now)
+
+			tt.reminder.Recipients = []string{"gardener@example.com"}
+			if _, err := test.service.CreateReminder(test.ctx, 1, tt.reminder); err != nil {
+				t.Fatal(err)
+			}
+
+			// the first tick materializes the first schedule, the dispatcher
+			// is down until the downtime is over
+			if err := test.dispatcher.Tick(test.ctx, test.clock.Now()); err != nil {
+				t.Fatal(err)
+			}
+			test.clock.Advance(tt.downtime)
+
+			test.advance(t, tt.advance)
+			if got := test.outbox.count(); got != tt.wantSent {
+				t.Errorf("sent %d emails, want %d", got, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestDispatcher_Tick_failedSchedule(t *testing.T) {
+	now := time.Date(2025, 7, 20, 9, 0, 0, 0, time.UTC)
+	test := newDispatcherTest(t, now)
+
+	for range 2 {
+		if _, err := test.service.CreateReminder(test.ctx, 1, internal.CreateReminderParams{
+			StartTime:  now.Add(10 * time.Minute).Format(time.RFC3339),
+			Recipients: []string{"gardener@example.com"},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := test.dispatcher.Tick(test.ctx, test.clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the misfire policy of the first reminder cannot load it, the second
+	// one is dispatched anyway
+	if _, err := test.db.ExecContext(test.ctx, "UPDATE reminders SET timezone = 'Mars/Olympus' WHERE id = 1"); err != nil {
+		t.Fatal(err)
+	}
+	test.clock.Advance(30 * time.Minute)
+	if err := test.dispatcher.Tick(test.ctx, test.clock.Now()); err != nil {
+		t.Fatalf("Tick() error = %v, want the failed schedule skipped", err)
+	}
+	if err := test.relay.Tick(test.ctx, test.clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := test.outbox.count(); got != 1 {
+		t.Errorf("sent %d emails, want 1", got)
+	}
+}
+
+func TestDispatcher_Run(t *testing.T) {
+	now := time.Date(2025, 7, 20, 9, 0, 0, 0, time.UTC)
+	test := newDispatcherTest(t, now)
+
+	_, err := test.service.CreateReminder(test.ctx, 1, internal.CreateReminderParams{
+		StartTime:    now.Format(time.RFC3339),
+		RepeatHourly: "1h",
+		Recipients:   []string{"gardener@example.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(test.ctx)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		test.dispatcher.Run(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		test.relay.Run(ctx)
+	}()
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// waitTimer waits until Run of the dispatcher and of the relay wait on
+	// their timers, advancing the clock before would leave a timer in the
+	// future
+	waitTimer := func() {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); test.clock.Timers() < 2; runtime.Gosched() {
+			if time.Now().After(deadline) {
+				t.Fatal("dispatcher does not wait on the clock")
+			}
+		}
+	}
+	expectEmail := func() {
+		t.Helper()
+		select {
+		case <-test.outbox.sent:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no email sent at %v", test.clock.Now())
+		}
+	}
+
+	// the first run is due when the dispatcher starts
+	waitTimer()
+	expectEmail()
+
+	for range 3 {
+		for range time.Hour / testInterval {
+			waitTimer()
+			test.clock.Advance(testInterval)
+		}
+		expectEmail()
+	}
+
+	if got := test.outbox.count(); got != 4 {
+		t.Errorf("sent %d emails, want 4", got)
+	}
+}
+
+func TestDispatcher_webhook(t *testing.T) {
+	now := time.Date(2025, 7, 20, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		status       int
+		wantCalls    int
+		wantStatus   internal.ActionStatus
+		wantAttempts int
+	}{
+		{name: "delivered", status: http.StatusOK, wantCalls: 1, wantStatus: internal.StatusSuccess},
+		{name: "client error is dead at once", status: http.StatusNotFound, wantCalls: 1, wantStatus: internal.StatusDead, wantAttempts: 1},
+		{name: "server error is retried by the policy", status: http.StatusServiceUnavailable, wantCalls: 3, wantStatus: internal.StatusDead, wantAttempts: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			test := newDispatcherTest(t, now)
+			_, err := test.service.CreateReminder(test.ctx, 1, internal.CreateReminderParams{
+				StartTime:  now.Add(10 * time.Minute).Format(time.RFC3339),
+				Channel:    internal.ChannelWebhook,
+				WebhookURL: srv.URL,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the retry policy waits one then two minutes between attempts
+			test.advance(t, 20*time.Minute)
+			if got := int(calls.Load()); got != tt.wantCalls {
+				t.Errorf("webhook called %d times, want %d", got, tt.wantCalls)
+			}
+
+			schedule, err := test.service.GetSchedule(test.ctx, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if schedule.Status != tt.wantStatus || schedule.Attempts != tt.wantAttempts || schedule.ResponseStatus != tt.status {
+				t.Errorf("schedule status = %v after %d attempts responding %d, want %v after %d attempts responding %d", schedule.Status, schedule.Attempts, schedule.ResponseStatus, tt.wantStatus, tt.wantAttempts, tt.status)
+			}
+		})
+	}
+}
+
+func TestDispatcher_lease(t *testing.T) {
+	now := time.Date(2025, 7, 20, 9, 0, 0, 0, time.UTC)
+	test := newDispatcherTest(t, now)
+
+	_, err := test.service.CreateReminder(test.ctx, 1, internal.CreateReminderParams{
+		StartTime:  now.Add(30 * time.Minute).Format(time.RFC3339),
+		Recipients: []string{"gardener@example.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := test.dispatcher.Tick(test.ctx, test.clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+	test.clock.Advance(30 * time.Minute)
+
+	// another dispatcher claimed the schedule and crashed before sending it
+	scheduleRepo := sqliterepo.NewScheduleRepository(test.db)
+	claimed, err := scheduleRepo.ClaimSchedule(test.ctx, 1, test.clock.Now(), test.clock.Now().Add(dispatchLease))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !claimed {
+		t.Fatal("schedule not claimed")
+	}
+
+	test.advance(t, time.Minute)
+	if got := test.outbox.count(); got != 0 {
+		t.Fatalf("sent %d emails during the lease, want 0", got)
+	}
+
+	test.advance(t, dispatchLease)
+	if got := test.outbox.count(); got != 1 {
+		t.Fatalf("sent %d emails after the lease, want 1", got)
+	}
+
+	schedule, err := scheduleRepo.GetSchedule(test.ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schedule.Status != internal.StatusSuccess || !schedule.RetryAt.IsZero() {
+		t.Errorf("schedule status = %v retried at %v, want %v and no retry", schedule.Status, schedule.RetryAt, internal.StatusSuccess)
+	}
+
+	test.advance(t, dispatchLease)
+	if got := test.outbox.count(); got != 1 {
+		t.Errorf("sent %d emails once delivered, want 1", got)
+	}
+}
+
+func TestDispatcher_UpdateReminder(t *testing.T) {
+	now := time.Date(2025, 7, 20, 9, 0, 0, 0, time.UTC)
+	test := newDispatcherTest(t, now)
+
+	_, err := test.service.CreateReminder(test.ctx, 1, internal.CreateReminderParams{
+		StartTime:    now.Add(10 * time.Minute).Format(time.RFC3339),
+		RepeatHourly: "1h",
+		Recipients:   []string{"gardener@example.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the run of 10:10 is pending once the first one is delivered
+	test.advance(t, 20*time.Minute)
+	if got := test.outbox.count(); got != 1 {
+		t.Fatalf("sent %d emails, want 1", got)
+	}
+
+	_, err = test.service.UpdateReminder(test.ctx, 1, internal.UpdateReminderParams{
+		StartTime:    now.Add(10 * time.Minute).Format(time.RFC3339),
+		RepeatHourly: "20m",
+		Recipients:   []string{"gardener@example.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the updated reminder runs every 20 minutes after the first run, from
+	// 9:30 instead of 10:10
+	test.advance(t, 10*time.Minute)
+	if got := test.outbox.count(); got != 2 {
+		t.Fatalf("sent %d emails at 9:30, want 2", got)
+	}
+	test.advance(t, 20*time.Minute)
+	if got := test.outbox.count(); got != 3 {
+		t.Errorf("sent %d emails at 9:50, want 3", got)
+	}
+}
+
+func TestDispatcher_Snooze(t *testing.T) {
+	now := time.Date(2025, 7, 20, 9, 0, 0, 0, time.UTC)
+	test := newDispatcherTest(t, now)
+
+	_, err := test.service.CreateReminder(test.ctx, 1, internal.CreateReminderParams{
+		StartTime:    now.Add(10 * time.Minute).Format(time.RFC3339),
+		RepeatHourly: "1h",
+		Recipients:   []string{"gardener@example.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	test.advance(t, 10*time.Minute)
+	if got := test.outbox.count(); got != 1 {
+		t.Fatalf("sent %d emails, want 1", got)
+	}
+
+	snooze, err := test.service.SnoozeSchedule(test.ctx, 1, internal.SnoozeScheduleParams{Duration: "20m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snooze.Kind != internal.ScheduleSnooze || snooze.ParentID != 1 || !snooze.NotifyAt.Equal(now.Add(30*time.Minute)) {
+		t.Errorf("SnoozeSchedule() = %+v", snooze)
+	}
+
+	if _, err := test.service.SnoozeSchedule(test.ctx, 1, internal.SnoozeScheduleParams{Duration: "20m"}); err == nil {
+		t.Errorf("SnoozeSchedule() snoozed a snoozed schedule")
+	}
+	if _, err := test.service.AcknowledgeSchedule(test.ctx, snooze.ID); err == nil {
+		t.Errorf("AcknowledgeSchedule() acknowledged a snooze that did not fire")
+	}
+
+	// the snooze fires 20 minutes later, the next hourly run is not delayed
+	test.advance(t, 20*time.Minute)
+	if got := test.outbox.count(); got != 2 {
+		t.Fatalf("sent %d emails after the snooze, want 2", got)
+	}
+
+	acknowledged, err := test.service.AcknowledgeSchedule(test.ctx, snooze.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acknowledged.Response != internal.ResponseAcknowledged || !acknowledged.RespondedAt.Equal(test.clock.Now()) {
+		t.Errorf("AcknowledgeSchedule() = %+v", acknowledged)
+	}
+
+	test.advance(t, 40*time.Minute)
+	if got := test.outbox.count(); got != 3 {
+		t.Errorf("sent %d emails after the next run, want 3", got)
+	}
+
+	reminder, err := test.service.GetReminder(test.ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reminder.Fired != 2 {
+		t.Errorf("reminder fired %d times, want 2 without the snooze", reminder.Fired)
+	}
+}
+
+func TestDispatcher_Escalation(t *testing.T) {
+	now := time.Date(2025, 7, 20, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		// acknowledge acknowledges the schedule of id acknowledgeID once the
+		// clock advanced by acknowledgeAfter, zero never acknowledges
+		acknowledgeAfter time.Duration
+		acknowledgeID    int64
+		want             []string
+	}{
+		{
+			name: "unacknowledged escalates through every step",
+			want: []string{"ops@example.com", "lead@example.com", "manager@example.com"},
+		},
+		{
+			name:             "acknowledged before the first step",
+			acknowledgeAfter: 10 * time.Minute,
+			acknowledgeID:    1,
+			want:             []string{"ops@example.com"},
+		},
+		{
+			name:             "acknowledged escalation ends the chain",
+			acknowledgeAfter: 20 * time.Minute,
+			acknowledgeID:    2,
+			want:             []string{"ops@example.com", "lead@example.com"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := newDispatcherTest(t, now)
+
+			policy, err := test.service.CreateEscalationPolicy(test.ctx, internal.CreateEscalationPolicyParams{
+				Name: "on call",
+				Steps: []internal.EscalationStep{
+					{After: "10m", Recipients: []string{"lead@example.com"}},
+					{After: "20m", Recipients: []string{"manager@example.com"}},
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = test.service.CreateReminder(test.ctx, 1, internal.CreateReminderParams{
+				StartTime:          now.Add(5 * time.Minute).Format(time.RFC3339),
+				Recipients:         []string{"ops@example.com"},
+				EscalationPolicyID: policy.ID,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.acknowledgeAfter > 0 {
+				test.advance(t, tt.acknowledgeAfter)
+				if _, err := test.service.AcknowledgeSchedule(test.ctx, tt.acknowledgeID); err != nil {
+					t.Fatal(err)
+				}
+			}
+			test.advance(t, 2*time.Hour-tt.acknowledgeAfter)
+
+			if got := test.outbox.recipients(); strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("sent emails to %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("unknown policy", func(t *testing.T) {
+		test := newDispatcherTest(t, now)
+
+		_, err := test.service.CreateReminder(test.ctx, 1, internal.CreateReminderParams{
+			StartTime:          now.Add(5 * time.Minute).Format(time.RFC3339),
+			Recipients:         []string{"ops@example.com"},
+			EscalationPolicyID: 7,
+		})
+		var validationErr internal.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("CreateReminder() error = %v, want a validation error", err)
+		}
+	})
+}
+
+func TestDispatcher_QuietHours(t *testing.T) {
+	now := time.Date(2025, 7, 20, 20, 0, 0, 0, time.UTC)
+	test := newDispatcherTest(t, now)
+
+	global, err := internal.NewQuietHours("", "22:00", "07:00", "", internal.QuietDefer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.dispatcher.quietHours = global
+
+	// owl is awake at night but not between 2 and 4
+	_, err = test.service.PutQuietHours(test.ctx, "Owl <OWL@example.com>", internal.PutQuietHoursParams{Start: "02:00", End: "04:00", Policy: internal.QuietDrop})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = test.service.CreateReminder(test.ctx, 1, internal.CreateReminderParams{
+		StartTime:    now.Add(time.Hour).Format(time.RFC3339),
+		RepeatHourly: "1h",
+		Recipients:   []string{"ops@example.com", "owl@example.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	test.advance(t, 12*time.Hour)
+
+	want := []string{
+		// 21:00
+		"ops@example.com,owl@example.com",
+		// 22:00 to 01:00, ops is deferred
+		"owl@example.com", "owl@example.com", "owl@example.com", "owl@example.com",
+		// 02:00 and 03:00 owl is dropped, 04:00 to 06:00
+		"owl@example.com", "owl@example.com", "owl@example.com",
+		// 07:00, the deferred runs notify ops once, then the run of 07:00
+		"ops@example.com", "ops@example.com,owl@example.com",
+		// 08:00
+		"ops@example.com,owl@example.com",
+	}
+	if got := test.outbox.recipients(); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("sent emails to\n%v\nwant\n%v", got, want)
+	}
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/elangreza/scheduler/internal"
+)
+
+func (s *TaskService) CreateEscalationPolicy(ctx context.Context, req internal.CreateEscalationPolicyParams) (*internal.EscalationPolicy, error) {
+	policy, err := internal.NewEscalationPolicy(req.Name, req.Steps)
+	if err != nil {
+		return nil, internal.ValidationError{Err: err}
+	}
+
+	policy.ID, err = s.escalationRepo.CreateEscalationPolicy(ctx, *policy)
+	if err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+func (s *TaskService) GetEscalationPolicy(ctx context.Context, id int64) (*internal.EscalationPolicy, error) {
+	return s.escalationRepo.GetEscalationPolicy(ctx, id)
+}
+
+func (s *TaskService) ListEscalationPolicies(ctx context.Context) ([]internal.EscalationPolicy, error) {
+	policies, err := s.escalationRepo.ListEscalationPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(policies) == 0 {
+		return []internal.EscalationPolicy{}, nil
+	}
+
+	return policies, nil
+}
+
+func (s *TaskService) UpdateEscalationPolicy(ctx context.Context, id int64, req internal.UpdateEscalationPolicyParams) (*internal.EscalationPolicy, error) {
+	current, err := s.escalationRepo.GetEscalationPolicy(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := internal.NewEscalationPolicy(req.Name, req.Steps)
+	if err != nil {
+		return nil, internal.ValidationError{Err: err}
+	}
+
+	policy.ID = current.ID
+	policy.CreatedAt = current.CreatedAt
+
+	if err := s.escalationRepo.UpdateEscalationPolicy(ctx, *policy); err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+func (s *TaskService) DeleteEscalationPolicy(ctx context.Context, id int64) error {
+	return s.escalationRepo.DeleteEscalationPolicy(ctx, id)
+}
+
+// attachEscalation sets the escalation policy of the reminder, a policy that
+// does not exist is invalid input
+func (s *TaskService) attachEscalation(ctx context.Context, reminder *internal.Reminder) error {
+	if reminder.EscalationPolicyID == 0 {
+		return nil
+	}
+
+	policy, err := s.escalationRepo.GetEscalationPolicy(ctx, reminder.EscalationPolicyID)
+	if errors.Is(err, internal.ErrNotFound) {
+		return internal.ValidationError{Err: err}
+	}
+	if err != nil {
+		return err
+	}
+
+	reminder.Escalation = policy
+	return nil
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+
+	"github.com/elangreza/scheduler/internal"
+)
+
+// PutQuietHours sets the quiet hours of a recipient, replacing the ones it had
+func (s *TaskService) PutQuietHours(ctx context.Context, recipient string, req internal.PutQuietHoursParams) (*internal.QuietHours, error) {
+	quietHours, err := internal.NewQuietHours(recipient, req.Start, req.End, req.Timezone, req.Policy)
+	if err != nil {
+		return nil, internal.ValidationError{Err: err}
+	}
+
+	if err := s.quietHoursRepo.PutQuietHours(ctx, *quietHours); err != nil {
+		return nil, err
+	}
+
+	return s.quietHoursRepo.GetQuietHours(ctx, quietHours.Recipient)
+}
+
+func (s *TaskService) GetQuietHours(ctx context.Context, recipient string) (*internal.QuietHours, error) {
+	return s.quietHoursRepo.GetQuietHours(ctx, quietHoursRecipient(recipient))
+}
+
+func (s *TaskService) ListQuietHours(ctx context.Context) ([]internal.QuietHours, error) {
+	list, err := s.quietHoursRepo.ListQuietHours(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) == 0 {
+		return []internal.QuietHours{}, nil
+	}
+
+	return list, nil
+}
+
+func (s *TaskService) DeleteQuietHours(ctx context.Context, recipient string) error {
+	return s.quietHoursRepo.DeleteQuietHours(ctx, quietHoursRecipient(recipient))
+}
+
+// quietHoursRecipient returns the recipient as quiet hours store it, an
+// invalid recipient is looked up as is and not found
+func quietHoursRecipient(recipient string) string {
+	if address, err := internal.RecipientAddress(recipient); err == nil {
+		return address
+	}
+	return recipient
+}
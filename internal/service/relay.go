@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/elangreza/scheduler/internal"
+	"github.com/elangreza/scheduler/internal/mailer"
+)
+
+const (
+	relayBatchSize = 100
+	// relayLease is how long a claimed entry is left to its relay before
+	// another one takes it over, in case the first one crashed sending it
+	relayLease = 5 * time.Minute
+)
+
+type (
+	outboxRepo interface {
+		ListDueOutboxEntries(ctx context.Context, now time.Time, limit int) ([]internal.OutboxEntry, error)
+		ClaimOutboxEntry(ctx context.Context, id int64, now, leaseUntil time.Time) (bool, error)
+		FinishOutboxEntry(ctx context.Context, entry internal.OutboxEntry) error
+		RetryOutboxEntry(ctx context.Context, entry internal.OutboxEntry) error
+	}
+
+	relaySender interface {
+		Send(msg mailer.Message) error
+	}
+
+	// Relay delivers the emails written to the outbox by the dispatcher.
+	// Failed deliveries are retried with the retry policy, every attempt
+	// sends the same message with the same Message-ID.
+	Relay struct {
+		outboxRepo  outboxRepo
+		sender      relaySender
+		retryPolicy internal.RetryPolicy
+		interval    time.Duration
+		clock       internal.Clock
+		wake        chan struct{}
+	}
+)
+
+func NewRelay(outboxRepo outboxRepo, sender relaySender, retryPolicy internal.RetryPolicy, interval time.Duration, clock internal.Clock) *Relay {
+	return &Relay{
+		outboxRepo:  outboxRepo,
+		sender:      sender,
+		retryPolicy: retryPolicy,
+		interval:    interval,
+		clock:       clock,
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// Notify wakes the relay up to deliver new entries without waiting for its
+// next tick
+func (r *Relay) Notify() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run ticks the relay every interval, or when notified, until ctx is
+// canceled
+func (r *Relay) Run(ctx context.Context) {
+	timer := r.clock.NewTimer(r.interval)
+	defer timer.Stop()
+
+	for {
+		if err := r.Tick(ctx, r.clock.Now()); err != nil {
+			log.Println("relay:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.wake:
+		case <-timer.C():
+			timer.Reset(r.interval)
+		}
+	}
+}
+
+// Tick delivers the due outbox entries
+func (r *Relay) Tick(ctx context.Context, now time.Time) error {
+	entries, err := r.outboxRepo.ListDueOutboxEntries(ctx, now, relayBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		claimed, err := r.outboxRepo.ClaimOutboxEntry(ctx, entry.ID, now, now.Add(relayLease))
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+
+		if err := r.deliver(ctx, entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deliver sends a claimed entry and stores the outcome. An entry failing
+// permanently, or after its retry policy is exhausted, is dead and so is its
+// schedule.
+func (r *Relay) deliver(ctx context.Context, entry internal.OutboxEntry) error {
+	var msg mailer.Message
+	err := json.Unmarshal(entry.Payload, &msg)
+	if err != nil {
+		// the payload is the same on every attempt
+		err = internal.PermanentError{Err: fmt.Errorf("invalid payload: %v", err)}
+	} else {
+		err = r.sender.Send(msg)
+	}
+	now := r.clock.Now()
+	entry.Attempts++
+	if err == nil {
+		entry.Status = internal.StatusSuccess
+		entry.SentAt = now
+		return r.outboxRepo.FinishOutboxEntry(ctx, entry)
+	}
+
+	entry.LastError = err.Error()
+	var permanent internal.PermanentError
+	if errors.As(err, &permanent) || r.retryPolicy.Exhausted(entry.Attempts) {
+		log.Printf("relay: outbox entry %s of schedule %d failed permanently after %d attempts: %v", entry.DedupeKey, entry.ScheduleID, entry.Attempts, err)
+		entry.Status = internal.StatusDead
+		return r.outboxRepo.FinishOutboxEntry(ctx, entry)
+	}
+
+	entry.NextAttemptAt = now.Add(r.retryPolicy.Delay(entry.Attempts))
+	log.Printf("relay: outbox entry %s of schedule %d failed, attempt %d retried at %s: %v", entry.DedupeKey, entry.ScheduleID, entry.Attempts, entry.NextAttemptAt.Format(time.RFC3339), err)
+	return r.outboxRepo.RetryOutboxEntry(ctx, entry)
+}
@@ -0,0 +1,313 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/elangreza/scheduler/internal"
+	"github.com/elangreza/scheduler/internal/mailer"
+	"github.com/elangreza/scheduler/internal/sqliterepo"
+)
+
+// flakyMailer fails the first failures emails it is asked to send
+type flakyMailer struct {
+	mu       sync.Mutex
+	failures int
+	sent     []mailer.Message
+}
+
+func (m *flakyMailer) Send(msg mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.failures > 0 {
+		m.failures--
+		return errors.New("connection refused")
+	}
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+type relayTest struct {
+	ctx          context.Context
+	db           *sql.DB
+	clock        *internal.FakeClock
+	mailer       *flakyMailer
+	scheduleRepo scheduleRepo
+	relay        *Relay
+	scheduleID   int64
+}
+
+func newRelayTest(t *testing.T, now time.Time, failures int) *relayTest {
+	t.Helper()
+
+	db, err := sqliterepo.NewSql(filepath.Join(t.TempDir(), "scheduler.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := sqliterepo.Migrate(db, "../../migrations"); err != nil {
+		t.Fatal(err)
+	}
+
+	test := &relayTest{
+		ctx:          context.Background(),
+		db:           db,
+		clock:        internal.NewFakeClock(now),
+		mailer:       &flakyMailer{failures: failures},
+		scheduleRepo: sqliterepo.NewScheduleRepository(db),
+	}
+	retryPolicy := internal.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour}
+	test.relay = NewRelay(sqliterepo.NewOutboxRepository(db), test.mailer, retryPolicy, time.Minute, test.clock)
+
+	task, err := internal.NewTask("water the plants", "every pot", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sqliterepo.NewTaskRepository(db).CreateTask(test.ctx, *task); err != nil {
+		t.Fatal(err)
+	}
+	// the first task of the database
+	const taskID = 1
+	reminderID, err := sqliterepo.NewReminderRepository(db).CreateReminder(test.ctx, internal.Reminder{TaskID: taskID, StartTime: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.scheduleID, err = test.scheduleRepo.CreateSchedule(test.ctx, *internal.NewSchedule(taskID, reminderID, now))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return test
+}
+
+// outbox finishes the schedule, writing its emails to the outbox
+func (r *relayTest) outbox(t *testing.T, subjects ...string) {
+	t.Helper()
+
+	var entries []internal.OutboxEntry
+	for i, subject := range subjects {
+		payload, err := json.Marshal(mailer.Message{To: []string{"gardener@example.com"}, Subject: subject, ID: subject})
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, *internal.NewOutboxEntry(r.scheduleID, i, payload, r.clock.Now()))
+	}
+
+	schedule := internal.Schedule{ID: r.scheduleID, Status: internal.StatusSuccess, DoneAt: r.clock.Now()}
+	if err := r.scheduleRepo.OutboxSchedule(r.ctx, schedule, entries); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// tick advances the clock by d and ticks the relay
+func (r *relayTest) tick(t *testing.T, d time.Duration) {
+	t.Helper()
+
+	r.clock.Advance(d)
+	if err := r.relay.Tick(r.ctx, r.clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// entries returns the status and attempts of the outbox entries, by dedupe
+// key
+func (r *relayTest) entries(t *testing.T) map[string][2]int {
+	t.Helper()
+
+	rows, err := r.db.QueryContext(r.ctx, "SELECT dedupe_key, status, attempts FROM outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	entries := make(map[string][2]int)
+	for rows.Next() {
+		var (
+			key              string
+			status, attempts int
+		)
+		if err := rows.Scan(&key, &status, &attempts); err != nil {
+			t.Fatal(err)
+		}
+		entries[key] = [2]int{status, attempts}
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+// schedule returns the status and last error of the schedule of the test
+func (r *relayTest) schedule(t *testing.T) (internal.ActionStatus, string) {
+	t.Helper()
+
+	var (
+		status    internal.ActionStatus
+		lastError sql.NullString
+	)
+	err := r.db.QueryRowContext(r.ctx, "SELECT status, last_error FROM schedules WHERE id = ?", r.scheduleID).Scan(&status, &lastError)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return status, lastError.String
+}
+
+func (r *relayTest) sent() []string {
+	r.mailer.mu.Lock()
+	defer r.mailer.mu.Unlock()
+
+	var ids []string
+	for _, msg := range r.mailer.sent {
+		ids = append(ids, msg.ID)
+	}
+	return ids
+}
+
+func TestRelay_Tick(t *testing.T) {
+	now := time.Date(2025, 7, 20, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		failures int
+		advance  time.Duration
+		want     map[string][2]int
+		wantSent int
+		// wantSchedule is the status of the schedule, delivered once its
+		// emails are written to the outbox
+		wantSchedule internal.ActionStatus
+	}{
+		{
+			name:         "sent",
+			want:         map[string][2]int{"schedule-1-0": {int(internal.StatusSuccess), 1}},
+			wantSent:     1,
+			wantSchedule: internal.StatusSuccess,
+		},
+		{
+			name:         "waiting for a retry",
+			failures:     1,
+			advance:      30 * time.Second,
+			want:         map[string][2]int{"schedule-1-0": {int(internal.StatusFailed), 1}},
+			wantSchedule: internal.StatusSuccess,
+		},
+		{
+			name:         "sent after a retry",
+			failures:     2,
+			advance:      5 * time.Minute,
+			want:         map[string][2]int{"schedule-1-0": {int(internal.StatusSuccess), 3}},
+			wantSent:     1,
+			wantSchedule: internal.StatusSuccess,
+		},
+		{
+			name:         "dead",
+			failures:     3,
+			advance:      time.Hour,
+			want:         map[string][2]int{"schedule-1-0": {int(internal.StatusDead), 3}},
+			wantSchedule: internal.StatusDead,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := newRelayTest(t, now, tt.failures)
+			test.outbox(t, "first")
+
+			test.tick(t, 0)
+			for elapsed := time.Duration(0); elapsed < tt.advance; elapsed += 30 * time.Second {
+				test.tick(t, 30*time.Second)
+			}
+
+			got := test.entries(t)
+			if len(got) != len(tt.want) {
+				t.Fatalf("outbox = %v, want %v", got, tt.want)
+			}
+			for key, want := range tt.want {
+				if got[key] != want {
+					t.Errorf("outbox entry %s = %v, want %v", key, got[key], want)
+				}
+			}
+			if got := len(test.sent()); got != tt.wantSent {
+				t.Errorf("sent %d emails, want %d", got, tt.wantSent)
+			}
+			if got, lastError := test.schedule(t); got != tt.wantSchedule {
+				t.Errorf("schedule status = %v (%s), want %v", got, lastError, tt.wantSchedule)
+			}
+		})
+	}
+}
+
+func TestRelay_invalidPayload(t *testing.T) {
+	now := time.Date(2025, 7, 20, 9, 0, 0, 0, time.UTC)
+	test := newRelayTest(t, now, 0)
+
+	schedule := internal.Schedule{ID: test.scheduleID, Status: internal.StatusSuccess, DoneAt: now}
+	entries := []internal.OutboxEntry{*internal.NewOutboxEntry(test.scheduleID, 0, []byte("{"), now)}
+	if err := test.scheduleRepo.OutboxSchedule(test.ctx, schedule, entries); err != nil {
+		t.Fatal(err)
+	}
+
+	// the payload is not retried, it would fail the same way every time
+	test.tick(t, 0)
+	if got, want := test.entries(t)["schedule-1-0"], [2]int{int(internal.StatusDead), 1}; got != want {
+		t.Errorf("outbox entry = %v, want %v", got, want)
+	}
+	if got, lastError := test.schedule(t); got != internal.StatusDead || !strings.Contains(lastError, "invalid payload") {
+		t.Errorf("schedule status = %v (%s), want %v with the payload error", got, lastError, internal.StatusDead)
+	}
+}
+
+func TestRelay_dedupe(t *testing.T) {
+	now := time.Date(2025, 7, 20, 9, 0, 0, 0, time.UTC)
+	test := newRelayTest(t, now, 0)
+
+	// the schedule is written to the outbox a second time, e.g., by a
+	// dispatcher that crashed before it stored the first delivery
+	test.outbox(t, "first", "second")
+	test.tick(t, 0)
+	test.outbox(t, "first again", "second again", "third")
+	test.tick(t, time.Minute)
+
+	got := test.sent()
+	want := []string{"first", "second", "third"}
+	if len(got) != len(want) {
+		t.Fatalf("sent %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("sent %v, want %v", got, want)
+		}
+	}
+}
+
+func TestRelay_lease(t *testing.T) {
+	now := time.Date(2025, 7, 20, 9, 0, 0, 0, time.UTC)
+	test := newRelayTest(t, now, 0)
+	test.outbox(t, "first")
+
+	// another relay claimed the entry and crashed before sending it
+	outboxRepo := sqliterepo.NewOutboxRepository(test.db)
+	claimed, err := outboxRepo.ClaimOutboxEntry(test.ctx, 1, now, now.Add(relayLease))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !claimed {
+		t.Fatal("entry not claimed")
+	}
+
+	test.tick(t, time.Minute)
+	if got := len(test.sent()); got != 0 {
+		t.Fatalf("sent %d emails during the lease, want 0", got)
+	}
+
+	test.tick(t, relayLease)
+	if got := len(test.sent()); got != 1 {
+		t.Errorf("sent %d emails after the lease, want 1", got)
+	}
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/elangreza/scheduler/internal"
+)
+
+const (
+	defaultOccurrenceCount = 10
+	maxOccurrenceCount     = 100
+)
+
+func (s *TaskService) CreateReminder(ctx context.Context, taskID int64, req internal.CreateReminderParams) (*internal.Reminder, error) {
+	reminder, err := newReminder(taskID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := attachCalendar(s.calendars, reminder); err != nil {
+		return nil, internal.ValidationError{Err: err}
+	}
+
+	if err := s.attachEscalation(ctx, reminder); err != nil {
+		return nil, err
+	}
+
+	reminder.ID, err = s.reminderRepo.CreateReminder(ctx, *reminder)
+	if err != nil {
+		return nil, err
+	}
+
+	return reminder, nil
+}
+
+func (s *TaskService) GetReminder(ctx context.Context, id int64) (*internal.Reminder, error) {
+	return s.reminderRepo.GetReminder(ctx, id)
+}
+
+func (s *TaskService) ListReminders(ctx context.Context, taskID int64) ([]internal.Reminder, error) {
+	reminders, err := s.reminderRepo.ListRemindersByTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(reminders) == 0 {
+		return []internal.Reminder{}, nil
+	}
+
+	return reminders, nil
+}
+
+func (s *TaskService) UpdateReminder(ctx context.Context, id int64, req internal.UpdateReminderParams) (*internal.Reminder, error) {
+	current, err := s.reminderRepo.GetReminder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	reminder, err := newReminder(current.TaskID, internal.CreateReminderParams(req))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := attachCalendar(s.calendars, reminder); err != nil {
+		return nil, internal.ValidationError{Err: err}
+	}
+
+	if err := s.attachEscalation(ctx, reminder); err != nil {
+		return nil, err
+	}
+
+	reminder.ID = current.ID
+	reminder.CreatedAt = current.CreatedAt
+	// the secret is never returned to callers, keep it unless a new one is sent
+	if reminder.WebhookSecret == "" {
+		reminder.WebhookSecret = current.WebhookSecret
+	}
+	reminder.Exceptions = current.Exceptions
+	reminder.Fired = current.Fired
+
+	if err := s.reminderRepo.UpdateReminder(ctx, *reminder); err != nil {
+		return nil, err
+	}
+
+	return reminder, nil
+}
+
+func (s *TaskService) DeleteReminder(ctx context.Context, id int64) error {
+	return s.reminderRepo.DeleteReminder(ctx, id)
+}
+
+// CreateReminderException skips a date or overrides an occurrence of a reminder
+func (s *TaskService) CreateReminderException(ctx context.Context, reminderID int64, req internal.CreateReminderExceptionParams) (*internal.ReminderException, error) {
+	reminder, err := s.reminderRepo.GetReminder(ctx, reminderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := attachCalendar(s.calendars, reminder); err != nil {
+		return nil, err
+	}
+
+	exception, err := internal.NewReminderException(reminderID, req.Kind, req.Date, req.OccurrenceAt, req.RunAt)
+	if err != nil {
+		return nil, internal.ValidationError{Err: err}
+	}
+
+	if exception.Kind == internal.ExceptionOverride && !reminder.IsOccurrence(exception.OccurrenceAt) {
+		return nil, internal.ValidationError{Err: fmt.Errorf("%s is not an occurrence of reminder %d", req.OccurrenceAt, reminderID)}
+	}
+
+	exception.ID, err = s.reminderRepo.CreateReminderException(ctx, *exception)
+	if err != nil {
+		return nil, err
+	}
+
+	return exception, nil
+}
+
+func (s *TaskService) ListReminderExceptions(ctx context.Context, reminderID int64) ([]internal.ReminderException, error) {
+	if _, err := s.reminderRepo.GetReminder(ctx, reminderID); err != nil {
+		return nil, err
+	}
+
+	exceptions, err := s.reminderRepo.ListReminderExceptions(ctx, reminderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(exceptions) == 0 {
+		return []internal.ReminderException{}, nil
+	}
+
+	return exceptions, nil
+}
+
+func (s *TaskService) DeleteReminderException(ctx context.Context, reminderID, id int64) error {
+	return s.reminderRepo.DeleteReminderException(ctx, reminderID, id)
+}
+
+// ListOccurrences returns the upcoming run times of a reminder
+func (s *TaskService) ListOccurrences(ctx context.Context, id int64, req internal.OccurrenceParams) ([]time.Time, error) {
+	reminder, err := s.reminderRepo.GetReminder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := attachCalendar(s.calendars, reminder); err != nil {
+		return nil, err
+	}
+
+	return occurrences(reminder, req, s.clock.Now())
+}
+
+// PreviewOccurrences returns the run times of an unsaved reminder, so a
+// recurrence can be checked before it is created
+func (s *TaskService) PreviewOccurrences(ctx context.Context, reminderReq internal.CreateReminderParams, req internal.OccurrenceParams) ([]time.Time, error) {
+	reminder, err := newReminder(0, reminderReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := attachCalendar(s.calendars, reminder); err != nil {
+		return nil, internal.ValidationError{Err: err}
+	}
+
+	return occurrences(reminder, req, s.clock.Now())
+}
+
+func occurrences(reminder *internal.Reminder, req internal.OccurrenceParams, now time.Time) ([]time.Time, error) {
+	if req.Count == 0 {
+		req.Count = defaultOccurrenceCount
+	}
+	if req.Count < 0 || req.Count > maxOccurrenceCount {
+		return nil, internal.ValidationError{Err: fmt.Errorf("count must be between 1 and %d", maxOccurrenceCount)}
+	}
+
+	if req.From.IsZero() {
+		req.From = now
+	}
+	if !req.Until.IsZero() && req.Until.Before(req.From) {
+		return nil, internal.ValidationError{Err: fmt.Errorf("until cannot be before from")}
+	}
+
+	occurrences := reminder.Occurrences(req.From, req.Until, req.Count)
+	if len(occurrences) == 0 {
+		return []time.Time{}, nil
+	}
+
+	return occurrences, nil
+}
+
+// newReminder builds and validates a reminder of a task from a request
+func newReminder(taskID int64, req internal.CreateReminderParams) (*internal.Reminder, error) {
+	reminder, err := internal.NewReminder(
+		taskID,
+		req.StartTime,
+		req.EndTime,
+		req.RepeatHourly,
+		req.RepeatDaily,
+		internal.WithRepeatMonthly(req.RepeatMonthly),
+		internal.WithRepeatYearly(req.RepeatYearly),
+		internal.WithCron(req.Cron),
+		internal.WithRRule(req.RRule),
+		internal.WithTimezone(req.Timezone),
+		internal.WithCalendar(req.Calendar, req.HolidayPolicy),
+		internal.WithMaxOccurrences(req.MaxOccurrences),
+		internal.WithMisfirePolicy(req.MisfirePolicy),
+		internal.WithEscalationPolicy(req.EscalationPolicyID),
+		internal.WithRecipients(req.Recipients...),
+		internal.WithChannel(req.Channel),
+		internal.WithWebhookURL(req.WebhookURL),
+		internal.WithWebhookSecret(req.WebhookSecret),
+	)
+	if err != nil {
+		return nil, internal.ValidationError{Err: err}
+	}
+
+	return reminder, nil
+}
+
+// ListCalendars returns the holiday calendars reminders can reference
+func (s *TaskService) ListCalendars(ctx context.Context) []internal.Calendar {
+	return s.calendars.List()
+}
+
+// attachCalendar sets the holiday calendar named by the reminder
+func attachCalendar(calendars calendars, reminder *internal.Reminder) error {
+	if reminder.Calendar == "" {
+		return nil
+	}
+
+	calendar, err := calendars.Get(reminder.Calendar)
+	if err != nil {
+		return err
+	}
+
+	reminder.SetCalendar(calendar)
+	return nil
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/elangreza/scheduler/internal"
+)
+
+func (s *TaskService) GetSchedule(ctx context.Context, id int64) (*internal.Schedule, error) {
+	return s.scheduleRepo.GetSchedule(ctx, id)
+}
+
+// AcknowledgeSchedule records that the recipient of a fired schedule saw it
+func (s *TaskService) AcknowledgeSchedule(ctx context.Context, id int64) (*internal.Schedule, error) {
+	schedule, err := s.scheduleRepo.GetSchedule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if schedule.Response == internal.ResponseAcknowledged {
+		return schedule, nil
+	}
+
+	if err := schedule.Acknowledge(s.clock.Now()); err != nil {
+		return nil, internal.ValidationError{Err: err}
+	}
+
+	if err := s.scheduleRepo.RespondSchedule(ctx, *schedule); err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+// SnoozeSchedule marks a fired schedule done and creates the schedule firing
+// it again, which is returned
+func (s *TaskService) SnoozeSchedule(ctx context.Context, id int64, req internal.SnoozeScheduleParams) (*internal.Schedule, error) {
+	now := s.clock.Now()
+	until, err := snoozeUntil(req, now)
+	if err != nil {
+		return nil, internal.ValidationError{Err: err}
+	}
+
+	schedule, err := s.scheduleRepo.GetSchedule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	snooze, err := schedule.Snooze(until, now)
+	if err != nil {
+		return nil, internal.ValidationError{Err: err}
+	}
+
+	snooze.ID, err = s.scheduleRepo.SnoozeSchedule(ctx, *schedule, *snooze)
+	if err != nil {
+		return nil, err
+	}
+
+	return snooze, nil
+}
+
+func snoozeUntil(req internal.SnoozeScheduleParams, now time.Time) (time.Time, error) {
+	switch {
+	case req.Duration != "" && req.Until != "":
+		return time.Time{}, fmt.Errorf("snooze accepts either a duration or an until time")
+	case req.Duration != "":
+		d, err := time.ParseDuration(req.Duration)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid snooze duration: %v", err)
+		}
+		if d <= 0 {
+			return time.Time{}, fmt.Errorf("snooze duration must be positive")
+		}
+		return now.Add(d), nil
+	case req.Until != "":
+		until, err := time.Parse(time.RFC3339, req.Until)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid snooze until format: %v", err)
+		}
+		return until, nil
+	default:
+		return time.Time{}, fmt.Errorf("snooze requires a duration or an until time")
+	}
+}
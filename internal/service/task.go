@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/elangreza/scheduler/internal"
 )
@@ -15,24 +16,79 @@ type (
 		// CreateSchedule(task *internal.Schedule) error
 	}
 
+	reminderRepo interface {
+		CreateReminder(ctx context.Context, reminder internal.Reminder) (int64, error)
+		GetReminder(ctx context.Context, id int64) (*internal.Reminder, error)
+		ListRemindersByTask(ctx context.Context, taskID int64) ([]internal.Reminder, error)
+		UpdateReminder(ctx context.Context, reminder internal.Reminder) error
+		DeleteReminder(ctx context.Context, id int64) error
+
+		CreateReminderException(ctx context.Context, exception internal.ReminderException) (int64, error)
+		ListReminderExceptions(ctx context.Context, reminderID int64) ([]internal.ReminderException, error)
+		DeleteReminderException(ctx context.Context, reminderID, id int64) error
+	}
+
+	taskScheduleRepo interface {
+		GetSchedule(ctx context.Context, id int64) (*internal.Schedule, error)
+		RespondSchedule(ctx context.Context, schedule internal.Schedule) error
+		SnoozeSchedule(ctx context.Context, schedule, snooze internal.Schedule) (int64, error)
+	}
+
+	escalationRepo interface {
+		CreateEscalationPolicy(ctx context.Context, policy internal.EscalationPolicy) (int64, error)
+		GetEscalationPolicy(ctx context.Context, id int64) (*internal.EscalationPolicy, error)
+		ListEscalationPolicies(ctx context.Context) ([]internal.EscalationPolicy, error)
+		UpdateEscalationPolicy(ctx context.Context, policy internal.EscalationPolicy) error
+		DeleteEscalationPolicy(ctx context.Context, id int64) error
+	}
+
+	quietHoursServiceRepo interface {
+		PutQuietHours(ctx context.Context, quietHours internal.QuietHours) error
+		GetQuietHours(ctx context.Context, recipient string) (*internal.QuietHours, error)
+		ListQuietHours(ctx context.Context) ([]internal.QuietHours, error)
+		DeleteQuietHours(ctx context.Context, recipient string) error
+	}
+
+	calendars interface {
+		Get(name string) (*internal.Calendar, error)
+		List() []internal.Calendar
+	}
+
+	// emailTemplates holds the email template sets tasks can reference
+	emailTemplates interface {
+		Has(name string) bool
+	}
+
 	TaskService struct {
-		sqlRepo sqlRepo
+		sqlRepo        sqlRepo
+		reminderRepo   reminderRepo
+		scheduleRepo   taskScheduleRepo
+		escalationRepo escalationRepo
+		quietHoursRepo quietHoursServiceRepo
+		calendars      calendars
+		emailTemplates emailTemplates
+		clock          internal.Clock
 	}
 )
 
-func NewTaskService(sqlRepo sqlRepo) *TaskService {
-	return &TaskService{sqlRepo: sqlRepo}
+func NewTaskService(sqlRepo sqlRepo, reminderRepo reminderRepo, scheduleRepo taskScheduleRepo, escalationRepo escalationRepo, quietHoursRepo quietHoursServiceRepo, calendars calendars, emailTemplates emailTemplates, clock internal.Clock) *TaskService {
+	return &TaskService{sqlRepo: sqlRepo, reminderRepo: reminderRepo, scheduleRepo: scheduleRepo, escalationRepo: escalationRepo, quietHoursRepo: quietHoursRepo, calendars: calendars, emailTemplates: emailTemplates, clock: clock}
 }
 
 func (s *TaskService) CreateTask(ctx context.Context, req internal.CreateTaskParams) error {
 	task, err := internal.NewTask(
 		req.Name,
 		req.Description,
+		req.EmailTemplate,
 	)
 	if err != nil {
 		return err
 	}
 
+	if err := s.checkEmailTemplate(task.EmailTemplate); err != nil {
+		return err
+	}
+
 	err = s.sqlRepo.CreateTask(ctx, *task)
 	if err != nil {
 		return err
@@ -59,5 +115,18 @@ func (s *TaskService) DeleteTask(ctx context.Context, id int) error {
 }
 
 func (s *TaskService) UpdateTask(ctx context.Context, id int, req internal.UpdateTaskParams) error {
+	if err := s.checkEmailTemplate(req.EmailTemplate); err != nil {
+		return err
+	}
+
 	return s.sqlRepo.UpdateTask(ctx, id, req)
 }
+
+// checkEmailTemplate checks that a task references a loaded email template
+// set, emails of tasks referencing a removed set fail to render
+func (s *TaskService) checkEmailTemplate(name string) error {
+	if !s.emailTemplates.Has(name) {
+		return internal.ValidationError{Err: fmt.Errorf("unknown email template %q", name)}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package smtpsink
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+type (
+	// Message is a captured email, with its decoded headers and bodies
+	Message struct {
+		ID int64 `json:"id"`
+		// From and To are the envelope addresses
+		From       string    `json:"from"`
+		To         []string  `json:"to"`
+		ReceivedAt time.Time `json:"received_at"`
+
+		MessageID   string       `json:"message_id"`
+		Subject     string       `json:"subject"`
+		Headers     mail.Header  `json:"headers"`
+		Text        string       `json:"text"`
+		HTML        string       `json:"html"`
+		Attachments []Attachment `json:"attachments"`
+		// Raw is the message as it was sent, with LF line endings
+		Raw []byte `json:"-"`
+		// Error is why the message could not be decoded, its bodies are then
+		// empty
+		Error string `json:"error,omitempty"`
+	}
+
+	Attachment struct {
+		Filename    string `json:"filename"`
+		ContentType string `json:"content_type"`
+		Data        []byte `json:"data"`
+	}
+)
+
+// parseMessage decodes the headers, bodies and attachments of a raw message
+func parseMessage(raw []byte) Message {
+	msg := Message{Raw: raw}
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		msg.Error = err.Error()
+		return msg
+	}
+
+	decoder := new(mime.WordDecoder)
+	msg.Headers = parsed.Header
+	msg.MessageID = parsed.Header.Get("Message-ID")
+	msg.Subject = parsed.Header.Get("Subject")
+	if subject, err := decoder.DecodeHeader(msg.Subject); err == nil {
+		msg.Subject = subject
+	}
+
+	if err := msg.walk(parsed.Header.Get("Content-Type"), parsed.Header.Get("Content-Transfer-Encoding"), parsed.Header.Get("Content-Disposition"), parsed.Body); err != nil {
+		msg.Error = err.Error()
+	}
+	return msg
+}
+
+// walk decodes a MIME entity into the bodies and attachments of the message,
+// the first text and html bodies are kept
+func (m *Message) walk(contentType, encoding, disposition string, body io.Reader) error {
+	if contentType == "" {
+		contentType = "text/plain"
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return err
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		r := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := r.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err := m.walk(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part.Header.Get("Content-Disposition"), part); err != nil {
+				return err
+			}
+		}
+	}
+
+	data, err := io.ReadAll(decodeTransfer(encoding, body))
+	if err != nil {
+		return err
+	}
+
+	dispositionType, dispositionParams, _ := mime.ParseMediaType(disposition)
+	switch {
+	case dispositionType != "attachment" && mediaType == "text/plain" && m.Text == "":
+		m.Text = string(data)
+	case dispositionType != "attachment" && mediaType == "text/html" && m.HTML == "":
+		m.HTML = string(data)
+	default:
+		filename := dispositionParams["filename"]
+		if filename == "" {
+			filename = params["name"]
+		}
+		m.Attachments = append(m.Attachments, Attachment{Filename: filename, ContentType: contentType, Data: data})
+	}
+	return nil
+}
+
+func decodeTransfer(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, &newlineStripper{r: body})
+	default:
+		return body
+	}
+}
+
+// newlineStripper drops the line breaks of a base64 body
+type newlineStripper struct {
+	r io.Reader
+}
+
+func (s *newlineStripper) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	kept := 0
+	for _, b := range p[:n] {
+		if b != '\r' && b != '\n' {
+			p[kept] = b
+			kept++
+		}
+	}
+	return kept, err
+}
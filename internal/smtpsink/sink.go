@@ -0,0 +1,268 @@
+// Package smtpsink is an SMTP server for development and tests. It accepts
+// every email sent to it on localhost and keeps them in memory instead of
+// delivering them.
+package smtpsink
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elangreza/scheduler/internal"
+)
+
+const (
+	// maxMessages is how many messages the sink keeps, the oldest ones are
+	// dropped first
+	maxMessages = 1000
+	// maxMessageSize is the largest message accepted, in bytes
+	maxMessageSize = 10 << 20
+	// sessionTimeout closes sessions idle for too long
+	sessionTimeout = 5 * time.Minute
+)
+
+// Sink captures the emails sent to it
+type Sink struct {
+	listener net.Listener
+	clock    internal.Clock
+
+	mu       sync.Mutex
+	messages []Message
+	lastID   int64
+	conns    map[net.Conn]struct{}
+	closed   bool
+
+	wg sync.WaitGroup
+}
+
+// Listen starts a sink on addr, e.g., "127.0.0.1:1025". The sink only
+// listens on loopback addresses, port 0 picks a free port.
+func Listen(addr string, clock internal.Clock) (*Sink, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return nil, fmt.Errorf("smtp sink must listen on localhost, not %q", host)
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Sink{listener: listener, clock: clock, conns: make(map[net.Conn]struct{})}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Addr returns the address the sink listens on
+func (s *Sink) Addr() *net.TCPAddr {
+	return s.listener.Addr().(*net.TCPAddr)
+}
+
+// Close stops the sink, ending its open sessions
+func (s *Sink) Close() error {
+	err := s.listener.Close()
+
+	s.mu.Lock()
+	s.closed = true
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return err
+}
+
+// Messages returns the captured messages, the most recent first
+func (s *Sink) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	messages := make([]Message, 0, len(s.messages))
+	for i := len(s.messages) - 1; i >= 0; i-- {
+		messages = append(messages, s.messages[i])
+	}
+	return messages
+}
+
+// Message returns a captured message by id
+func (s *Sink) Message(id int64) (*Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, msg := range s.messages {
+		if msg.ID == id {
+			return &msg, nil
+		}
+	}
+	return nil, fmt.Errorf("message %d %w", id, internal.ErrNotFound)
+}
+
+// Clear drops the captured messages
+func (s *Sink) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messages = nil
+}
+
+func (s *Sink) store(from string, to []string, raw []byte) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastID++
+	msg := parseMessage(raw)
+	msg.ID = s.lastID
+	msg.From = from
+	msg.To = to
+	msg.ReceivedAt = s.clock.Now()
+
+	s.messages = append(s.messages, msg)
+	if len(s.messages) > maxMessages {
+		s.messages = s.messages[len(s.messages)-maxMessages:]
+	}
+	return msg.ID
+}
+
+func (s *Sink) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Println("smtp sink:", err)
+			}
+			return
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer func() {
+				s.mu.Lock()
+				delete(s.conns, conn)
+				s.mu.Unlock()
+				conn.Close()
+			}()
+
+			if err := s.session(conn); err != nil {
+				log.Println("smtp sink:", err)
+			}
+		}()
+	}
+}
+
+// session speaks the subset of SMTP used to send mail, without TLS or auth
+func (s *Sink) session(conn net.Conn) error {
+	tp := textproto.NewConn(conn)
+
+	var (
+		from       string
+		recipients []string
+		started    bool
+	)
+	reply := func(format string, args ...any) error {
+		conn.SetDeadline(time.Now().Add(sessionTimeout))
+		return tp.PrintfLine(format, args...)
+	}
+
+	if err := reply("220 localhost scheduler SMTP sink"); err != nil {
+		return err
+	}
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return nil
+		}
+
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			err = reply("250-localhost\r\n250-8BITMIME\r\n250-SMTPUTF8\r\n250 SIZE %d", maxMessageSize)
+		case "HELO":
+			err = reply("250 localhost")
+		case "MAIL":
+			address, ok := pathArg(arg, "FROM:")
+			if !ok {
+				err = reply("501 syntax: MAIL FROM:<address>")
+				break
+			}
+			from, recipients, started = address, nil, true
+			err = reply("250 OK")
+		case "RCPT":
+			address, ok := pathArg(arg, "TO:")
+			switch {
+			case !started:
+				err = reply("503 MAIL first")
+			case !ok || address == "":
+				err = reply("501 syntax: RCPT TO:<address>")
+			default:
+				recipients = append(recipients, address)
+				err = reply("250 OK")
+			}
+		case "DATA":
+			if len(recipients) == 0 {
+				err = reply("503 RCPT first")
+				break
+			}
+			if err := reply("354 end data with <CR><LF>.<CR><LF>"); err != nil {
+				return err
+			}
+			raw, readErr := tp.ReadDotBytes()
+			if readErr != nil {
+				return readErr
+			}
+			if len(raw) > maxMessageSize {
+				err = reply("552 message exceeds %d bytes", maxMessageSize)
+			} else {
+				id := s.store(from, recipients, raw)
+				err = reply("250 OK queued as %d", id)
+			}
+			from, recipients, started = "", nil, false
+		case "RSET":
+			from, recipients, started = "", nil, false
+			err = reply("250 OK")
+		case "NOOP":
+			err = reply("250 OK")
+		case "QUIT":
+			return reply("221 bye")
+		default:
+			err = reply("502 %s not implemented", verb)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// pathArg returns the address of a MAIL or RCPT argument, e.g.,
+// "FROM:<ops@example.com> BODY=8BITMIME"
+func pathArg(arg, prefix string) (string, bool) {
+	if len(arg) < len(prefix) || !strings.EqualFold(arg[:len(prefix)], prefix) {
+		return "", false
+	}
+	path, _, _ := strings.Cut(strings.TrimSpace(arg[len(prefix):]), " ")
+	if !strings.HasPrefix(path, "<") || !strings.HasSuffix(path, ">") {
+		return "", false
+	}
+	return path[1 : len(path)-1], true
+}
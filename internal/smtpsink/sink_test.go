@@ -0,0 +1,187 @@
+package smtpsink
+
+import (
+	"errors"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elangreza/scheduler/config"
+	"github.com/elangreza/scheduler/internal"
+	"github.com/elangreza/scheduler/internal/mailer"
+)
+
+func newTestSink(t *testing.T, now time.Time) *Sink {
+	t.Helper()
+
+	sink, err := Listen("127.0.0.1:0", internal.NewFakeClock(now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sink.Close() })
+	return sink
+}
+
+func TestSink_mailer(t *testing.T) {
+	now := time.Date(2025, 7, 20, 9, 0, 0, 0, time.UTC)
+	sink := newTestSink(t, now)
+
+	m, err := mailer.NewMailer(&config.Config{
+		SmtpHost:        "127.0.0.1",
+		SmtpPort:        sink.Addr().Port,
+		SmtpTLS:         mailer.TLSNone,
+		SmtpFrom:        "Scheduler <scheduler@example.com>",
+		SmtpPoolSize:    1,
+		SmtpIdleTimeout: time.Minute,
+	}, internal.NewFakeClock(now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	tests := []struct {
+		name string
+		msg  mailer.Message
+		want Message
+	}{
+		{
+			name: "text",
+			msg: mailer.Message{
+				To:      []string{"Gärtner <gardener@example.com>"},
+				Subject: "Gießen: every pot",
+				Text:    "water the plants\n.\nthen rest",
+				ID:      "text",
+			},
+			want: Message{
+				From:      "scheduler@example.com",
+				To:        []string{"gardener@example.com"},
+				MessageID: "<text@example.com>",
+				Subject:   "Gießen: every pot",
+				// the line ending the DATA command is part of the body
+				Text: "water the plants\n.\nthen rest\n",
+			},
+		},
+		{
+			name: "html with an attachment",
+			msg: mailer.Message{
+				To:      []string{"gardener@example.com"},
+				Cc:      []string{"ops@example.com"},
+				Subject: "water the plants",
+				Text:    "every pot",
+				HTML:    "<p>every pot</p>",
+				Attachments: []mailer.Attachment{
+					{Filename: "invite.ics", ContentType: "text/calendar", Data: []byte("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n")},
+				},
+				ID: "html",
+			},
+			want: Message{
+				From:      "scheduler@example.com",
+				To:        []string{"gardener@example.com", "ops@example.com"},
+				MessageID: "<html@example.com>",
+				Subject:   "water the plants",
+				Text:      "every pot",
+				HTML:      "<p>every pot</p>",
+				Attachments: []Attachment{
+					{Filename: "invite.ics", ContentType: "text/calendar; name=invite.ics", Data: []byte("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n")},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := m.Send(tt.msg); err != nil {
+				t.Fatal(err)
+			}
+
+			got := sink.Messages()[0]
+			if got.Error != "" {
+				t.Fatalf("message not decoded: %s", got.Error)
+			}
+			if got.From != tt.want.From || strings.Join(got.To, " ") != strings.Join(tt.want.To, " ") {
+				t.Errorf("envelope = %s to %v, want %s to %v", got.From, got.To, tt.want.From, tt.want.To)
+			}
+			if got.MessageID != tt.want.MessageID {
+				t.Errorf("MessageID = %q, want %q", got.MessageID, tt.want.MessageID)
+			}
+			if got.Subject != tt.want.Subject {
+				t.Errorf("Subject = %q, want %q", got.Subject, tt.want.Subject)
+			}
+			if got.Text != tt.want.Text {
+				t.Errorf("Text = %q, want %q", got.Text, tt.want.Text)
+			}
+			if got.HTML != tt.want.HTML {
+				t.Errorf("HTML = %q, want %q", got.HTML, tt.want.HTML)
+			}
+			if len(got.Attachments) != len(tt.want.Attachments) {
+				t.Fatalf("Attachments = %v, want %v", got.Attachments, tt.want.Attachments)
+			}
+			for i, want := range tt.want.Attachments {
+				attachment := got.Attachments[i]
+				if attachment.Filename != want.Filename || attachment.ContentType != want.ContentType || string(attachment.Data) != string(want.Data) {
+					t.Errorf("Attachments[%d] = %+v, want %+v", i, attachment, want)
+				}
+			}
+			if !got.ReceivedAt.Equal(now) {
+				t.Errorf("ReceivedAt = %v, want %v", got.ReceivedAt, now)
+			}
+		})
+	}
+}
+
+func TestSink_Messages(t *testing.T) {
+	sink := newTestSink(t, time.Date(2025, 7, 20, 9, 0, 0, 0, time.UTC))
+
+	for _, subject := range []string{"first", "second"} {
+		body := "Subject: " + subject + "\r\n\r\n" + subject + "\r\n"
+		if err := smtp.SendMail(sink.Addr().String(), nil, "scheduler@example.com", []string{"gardener@example.com"}, []byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	messages := sink.Messages()
+	if len(messages) != 2 || messages[0].Subject != "second" || messages[1].Subject != "first" {
+		t.Fatalf("Messages() = %v, want second then first", messages)
+	}
+
+	first, err := sink.Message(messages[1].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(first.Raw) != "Subject: first\n\nfirst\n" {
+		t.Errorf("Raw = %q", first.Raw)
+	}
+
+	sink.Clear()
+	if got := sink.Messages(); len(got) != 0 {
+		t.Errorf("Messages() after Clear() = %v, want none", got)
+	}
+	if _, err := sink.Message(first.ID); !errors.Is(err, internal.ErrNotFound) {
+		t.Errorf("Message() after Clear() error = %v, want %v", err, internal.ErrNotFound)
+	}
+}
+
+func TestListen(t *testing.T) {
+	tests := []struct {
+		name    string
+		addr    string
+		wantErr bool
+	}{
+		{name: "ipv4 loopback", addr: "127.0.0.1:0"},
+		{name: "localhost", addr: "localhost:0"},
+		{name: "every interface", addr: ":0", wantErr: true},
+		{name: "unspecified", addr: "0.0.0.0:0", wantErr: true},
+		{name: "no port", addr: "127.0.0.1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink, err := Listen(tt.addr, internal.SystemClock{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Listen() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if sink != nil {
+				sink.Close()
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package sqliterepo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/elangreza/scheduler/internal"
+)
+
+const escalationPolicyColumns = "id, name, steps, created_at, updated_at"
+
+type escalationRepository struct {
+	db *sql.DB
+}
+
+func NewEscalationRepository(db *sql.DB) *escalationRepository {
+	return &escalationRepository{
+		db: db,
+	}
+}
+
+func (r *escalationRepository) CreateEscalationPolicy(ctx context.Context, policy internal.EscalationPolicy) (int64, error) {
+	steps, err := json.Marshal(policy.Steps)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := r.db.ExecContext(ctx, "INSERT INTO escalation_policies (name, steps) VALUES (?, ?)",
+		policy.Name,
+		string(steps),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func (r *escalationRepository) GetEscalationPolicy(ctx context.Context, id int64) (*internal.EscalationPolicy, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+escalationPolicyColumns+" FROM escalation_policies WHERE id = ?", id)
+
+	policy, err := scanEscalationPolicy(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("escalation policy %d %w", id, internal.ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+func (r *escalationRepository) ListEscalationPolicies(ctx context.Context) ([]internal.EscalationPolicy, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+escalationPolicyColumns+" FROM escalation_policies ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var policies []internal.EscalationPolicy
+	for rows.Next() {
+		policy, err := scanEscalationPolicy(rows)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, *policy)
+	}
+	return policies, rows.Err()
+}
+
+// UpdateEscalationPolicy replaces the steps of a policy, escalations already
+// enqueued notify the step of the same number in the new steps
+func (r *escalationRepository) UpdateEscalationPolicy(ctx context.Context, policy internal.EscalationPolicy) error {
+	steps, err := json.Marshal(policy.Steps)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.db.ExecContext(ctx, "UPDATE escalation_policies SET name = ?, steps = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
+		policy.Name,
+		string(steps),
+		policy.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(res, "escalation policy", policy.ID)
+}
+
+// DeleteEscalationPolicy removes a policy, reminders using it stop escalating
+func (r *escalationRepository) DeleteEscalationPolicy(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM escalation_policies WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(res, "escalation policy", id)
+}
+
+func scanEscalationPolicy(row scanner) (*internal.EscalationPolicy, error) {
+	var (
+		policy internal.EscalationPolicy
+		steps  string
+	)
+
+	err := row.Scan(
+		&policy.ID,
+		&policy.Name,
+		&steps,
+		&policy.CreatedAt,
+		&policy.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(steps), &policy.Steps); err != nil {
+		return nil, fmt.Errorf("invalid steps of escalation policy %d: %v", policy.ID, err)
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid stored escalation policy %d: %v", policy.ID, err)
+	}
+
+	return &policy, nil
+}
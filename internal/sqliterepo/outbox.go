@@ -0,0 +1,165 @@
+package sqliterepo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/elangreza/scheduler/internal"
+)
+
+const outboxColumns = "id, schedule_id, dedupe_key, payload, status, attempts, last_error, next_attempt_at, sent_at, created_at, updated_at"
+
+type outboxRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) *outboxRepository {
+	return &outboxRepository{
+		db: db,
+	}
+}
+
+// ListDueOutboxEntries returns the entries waiting for a delivery attempt
+// whose time is not after now, including entries still sending after their
+// lease, whose relay crashed
+func (r *outboxRepository) ListDueOutboxEntries(ctx context.Context, now time.Time, limit int) ([]internal.OutboxEntry, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+outboxColumns+" FROM outbox WHERE status IN (?, ?, ?) AND next_attempt_at <= ? ORDER BY next_attempt_at, id LIMIT ?",
+		internal.StatusCreated,
+		internal.StatusSending,
+		internal.StatusFailed,
+		now.UTC(),
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []internal.OutboxEntry
+	for rows.Next() {
+		entry, err := scanOutboxEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+	return entries, rows.Err()
+}
+
+// ClaimOutboxEntry marks a due entry as sending until leaseUntil. It returns
+// false when the entry is no longer due, which means another relay already
+// took it.
+func (r *outboxRepository) ClaimOutboxEntry(ctx context.Context, id int64, now, leaseUntil time.Time) (bool, error) {
+	res, err := r.db.ExecContext(ctx, "UPDATE outbox SET status = ?, next_attempt_at = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND status IN (?, ?, ?) AND next_attempt_at <= ?",
+		internal.StatusSending,
+		leaseUntil.UTC(),
+		id,
+		internal.StatusCreated,
+		internal.StatusSending,
+		internal.StatusFailed,
+		now.UTC(),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// FinishOutboxEntry stores the final status of a delivered or dead entry. A
+// dead entry marks its schedule dead with the same error in the same
+// transaction, the schedule was finished as delivered once its entries were
+// written.
+func (r *outboxRepository) FinishOutboxEntry(ctx context.Context, entry internal.OutboxEntry) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "UPDATE outbox SET status = ?, attempts = ?, last_error = ?, sent_at = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
+		entry.Status,
+		entry.Attempts,
+		entry.LastError,
+		nullTime(entry.SentAt.UTC()),
+		entry.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := expectAffected(res, "outbox entry", entry.ID); err != nil {
+		return err
+	}
+
+	if entry.Status == internal.StatusDead {
+		res, err := tx.ExecContext(ctx, "UPDATE schedules SET status = ?, last_error = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
+			internal.StatusDead,
+			entry.LastError,
+			entry.ScheduleID,
+		)
+		if err != nil {
+			return err
+		}
+
+		if err := expectAffected(res, "schedule", entry.ScheduleID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// RetryOutboxEntry stores a failed delivery of an entry that will be
+// attempted again at its next attempt time
+func (r *outboxRepository) RetryOutboxEntry(ctx context.Context, entry internal.OutboxEntry) error {
+	res, err := r.db.ExecContext(ctx, "UPDATE outbox SET status = ?, attempts = ?, last_error = ?, next_attempt_at = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
+		internal.StatusFailed,
+		entry.Attempts,
+		entry.LastError,
+		entry.NextAttemptAt.UTC(),
+		entry.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(res, "outbox entry", entry.ID)
+}
+
+func scanOutboxEntry(row scanner) (*internal.OutboxEntry, error) {
+	var (
+		entry     internal.OutboxEntry
+		payload   string
+		lastError sql.NullString
+		sentAt    sql.NullTime
+	)
+
+	err := row.Scan(
+		&entry.ID,
+		&entry.ScheduleID,
+		&entry.DedupeKey,
+		&payload,
+		&entry.Status,
+		&entry.Attempts,
+		&lastError,
+		&entry.NextAttemptAt,
+		&sentAt,
+		&entry.CreatedAt,
+		&entry.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.Payload = []byte(payload)
+	entry.LastError = lastError.String
+	entry.SentAt = sentAt.Time
+	return &entry, nil
+}
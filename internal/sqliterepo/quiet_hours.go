@@ -0,0 +1,108 @@
+package sqliterepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/elangreza/scheduler/internal"
+)
+
+const quietHoursColumns = "recipient, start_time, end_time, timezone, policy, created_at, updated_at"
+
+type quietHoursRepository struct {
+	db *sql.DB
+}
+
+func NewQuietHoursRepository(db *sql.DB) *quietHoursRepository {
+	return &quietHoursRepository{
+		db: db,
+	}
+}
+
+// PutQuietHours stores the quiet hours of a recipient, replacing the ones it
+// had
+func (r *quietHoursRepository) PutQuietHours(ctx context.Context, quietHours internal.QuietHours) error {
+	_, err := r.db.ExecContext(ctx, "INSERT INTO quiet_hours (recipient, start_time, end_time, timezone, policy) VALUES (?, ?, ?, ?, ?) ON CONFLICT (recipient) DO UPDATE SET start_time = excluded.start_time, end_time = excluded.end_time, timezone = excluded.timezone, policy = excluded.policy, updated_at = CURRENT_TIMESTAMP",
+		quietHours.Recipient,
+		quietHours.Start,
+		quietHours.End,
+		quietHours.Timezone,
+		quietHours.Policy,
+	)
+	return err
+}
+
+func (r *quietHoursRepository) GetQuietHours(ctx context.Context, recipient string) (*internal.QuietHours, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+quietHoursColumns+" FROM quiet_hours WHERE recipient = ?", recipient)
+
+	quietHours, err := scanQuietHours(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("quiet hours of %s %w", recipient, internal.ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return quietHours, nil
+}
+
+func (r *quietHoursRepository) ListQuietHours(ctx context.Context) ([]internal.QuietHours, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+quietHoursColumns+" FROM quiet_hours ORDER BY recipient")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []internal.QuietHours
+	for rows.Next() {
+		quietHours, err := scanQuietHours(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *quietHours)
+	}
+	return list, rows.Err()
+}
+
+func (r *quietHoursRepository) DeleteQuietHours(ctx context.Context, recipient string) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM quiet_hours WHERE recipient = ?", recipient)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("quiet hours of %s %w", recipient, internal.ErrNotFound)
+	}
+
+	return nil
+}
+
+func scanQuietHours(row scanner) (*internal.QuietHours, error) {
+	var quietHours internal.QuietHours
+
+	err := row.Scan(
+		&quietHours.Recipient,
+		&quietHours.Start,
+		&quietHours.End,
+		&quietHours.Timezone,
+		&quietHours.Policy,
+		&quietHours.CreatedAt,
+		&quietHours.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := quietHours.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid stored quiet hours of %s: %v", quietHours.Recipient, err)
+	}
+
+	return &quietHours, nil
+}
@@ -0,0 +1,394 @@
+package sqliterepo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/elangreza/scheduler/internal"
+	"github.com/mattn/go-sqlite3"
+)
+
+const reminderColumns = "id, task_id, start_time, end_time, repeat_hourly, repeat_daily, repeat_monthly, repeat_yearly, cron, rrule, timezone, calendar, holiday_policy, max_occurrences, misfire_policy, escalation_policy_id, recipients, channel, webhook_url, webhook_secret, created_at, updated_at"
+
+type reminderRepository struct {
+	db *sql.DB
+}
+
+func NewReminderRepository(db *sql.DB) *reminderRepository {
+	return &reminderRepository{
+		db: db,
+	}
+}
+
+func (r *reminderRepository) CreateReminder(ctx context.Context, reminder internal.Reminder) (int64, error) {
+	repeatDaily, err := json.Marshal(reminder.RepeatDaily)
+	if err != nil {
+		return 0, err
+	}
+
+	recipients, err := json.Marshal(reminder.Recipients)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := r.db.ExecContext(ctx, "INSERT INTO reminders (task_id, start_time, end_time, repeat_hourly, repeat_daily, repeat_monthly, repeat_yearly, cron, rrule, timezone, calendar, holiday_policy, max_occurrences, misfire_policy, escalation_policy_id, recipients, channel, webhook_url, webhook_secret) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		reminder.TaskID,
+		reminder.StartTime,
+		nullTime(reminder.EndTime),
+		reminder.RepeatHourly,
+		string(repeatDaily),
+		reminder.RepeatMonthly,
+		reminder.RepeatYearly,
+		reminder.Cron,
+		reminder.RRule,
+		reminder.Timezone,
+		reminder.Calendar,
+		reminder.HolidayPolicy,
+		reminder.MaxOccurrences,
+		reminder.MisfirePolicy,
+		nullInt64(reminder.EscalationPolicyID),
+		string(recipients),
+		reminder.DeliveryChannel(),
+		reminder.WebhookURL,
+		reminder.WebhookSecret,
+	)
+	if err != nil {
+		var sqliteErr sqlite3.Error
+		if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintForeignKey {
+			return 0, fmt.Errorf("task %d %w", reminder.TaskID, internal.ErrNotFound)
+		}
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func (r *reminderRepository) GetReminder(ctx context.Context, id int64) (*internal.Reminder, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+reminderColumns+" FROM reminders WHERE id = ?", id)
+
+	reminder, err := scanReminder(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("reminder %d %w", id, internal.ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	reminders := []internal.Reminder{*reminder}
+	if err := r.attach(ctx, reminders); err != nil {
+		return nil, err
+	}
+
+	return &reminders[0], nil
+}
+
+func (r *reminderRepository) ListRemindersByTask(ctx context.Context, taskID int64) ([]internal.Reminder, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+reminderColumns+" FROM reminders WHERE task_id = ? ORDER BY id", taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reminders []internal.Reminder
+	for rows.Next() {
+		reminder, err := scanReminder(rows)
+		if err != nil {
+			return nil, err
+		}
+		reminders = append(reminders, *reminder)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return reminders, r.attach(ctx, reminders)
+}
+
+// ListIdleReminders returns reminders that have no schedule waiting for its
+// first delivery, these are the reminders that need their next schedule to be
+// materialized. Schedules waiting for a retry and snoozes do not block the
+// next one.
+func (r *reminderRepository) ListIdleReminders(ctx context.Context) ([]internal.Reminder, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+reminderColumns+" FROM reminders WHERE NOT EXISTS (SELECT 1 FROM schedules WHERE schedules.reminder_id = reminders.id AND schedules.status = ? AND schedules.kind = ?) ORDER BY id", internal.StatusCreated, internal.ScheduleOccurrence)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reminders []internal.Reminder
+	for rows.Next() {
+		reminder, err := scanReminder(rows)
+		if err != nil {
+			return nil, err
+		}
+		reminders = append(reminders, *reminder)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return reminders, r.attach(ctx, reminders)
+}
+
+// UpdateReminder stores the reminder and removes its pending schedules in the
+// same transaction, so the dispatcher materializes the next one from the
+// updated recurrence
+func (r *reminderRepository) UpdateReminder(ctx context.Context, reminder internal.Reminder) error {
+	repeatDaily, err := json.Marshal(reminder.RepeatDaily)
+	if err != nil {
+		return err
+	}
+
+	recipients, err := json.Marshal(reminder.Recipients)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "UPDATE reminders SET start_time = ?, end_time = ?, repeat_hourly = ?, repeat_daily = ?, repeat_monthly = ?, repeat_yearly = ?, cron = ?, rrule = ?, timezone = ?, calendar = ?, holiday_policy = ?, max_occurrences = ?, misfire_policy = ?, escalation_policy_id = ?, recipients = ?, channel = ?, webhook_url = ?, webhook_secret = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
+		reminder.StartTime,
+		nullTime(reminder.EndTime),
+		reminder.RepeatHourly,
+		string(repeatDaily),
+		reminder.RepeatMonthly,
+		reminder.RepeatYearly,
+		reminder.Cron,
+		reminder.RRule,
+		reminder.Timezone,
+		reminder.Calendar,
+		reminder.HolidayPolicy,
+		reminder.MaxOccurrences,
+		reminder.MisfirePolicy,
+		nullInt64(reminder.EscalationPolicyID),
+		string(recipients),
+		reminder.DeliveryChannel(),
+		reminder.WebhookURL,
+		reminder.WebhookSecret,
+		reminder.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := expectAffected(res, "reminder", reminder.ID); err != nil {
+		return err
+	}
+
+	if err := deletePendingSchedules(ctx, tx, reminder.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *reminderRepository) DeleteReminder(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM reminders WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(res, "reminder", id)
+}
+
+// attach loads the state of reminders stored apart from them
+func (r *reminderRepository) attach(ctx context.Context, reminders []internal.Reminder) error {
+	if err := r.attachExceptions(ctx, reminders); err != nil {
+		return err
+	}
+
+	if err := r.attachFired(ctx, reminders); err != nil {
+		return err
+	}
+
+	return r.attachEscalations(ctx, reminders)
+}
+
+// attachEscalations loads the escalation policies of reminders into their
+// Escalation
+func (r *reminderRepository) attachEscalations(ctx context.Context, reminders []internal.Reminder) error {
+	var args []any
+	for _, reminder := range reminders {
+		if reminder.EscalationPolicyID != 0 {
+			args = append(args, reminder.EscalationPolicyID)
+		}
+	}
+	if len(args) == 0 {
+		return nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")
+	rows, err := r.db.QueryContext(ctx, "SELECT "+escalationPolicyColumns+" FROM escalation_policies WHERE id IN ("+placeholders+")", args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	policies := make(map[int64]*internal.EscalationPolicy)
+	for rows.Next() {
+		policy, err := scanEscalationPolicy(rows)
+		if err != nil {
+			return err
+		}
+		policies[policy.ID] = policy
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range reminders {
+		reminders[i].Escalation = policies[reminders[i].EscalationPolicyID]
+	}
+	return nil
+}
+
+// attachFired counts the schedules of reminders that fired into their Fired.
+// Schedules waiting for a retry count, they end as success or dead. Missed
+// schedules never fired, snoozes fire an occurrence again.
+func (r *reminderRepository) attachFired(ctx context.Context, reminders []internal.Reminder) error {
+	if len(reminders) == 0 {
+		return nil
+	}
+
+	index := make(map[int64]int, len(reminders))
+	args := make([]any, 0, len(reminders)+4)
+	for i, reminder := range reminders {
+		index[reminder.ID] = i
+		args = append(args, reminder.ID)
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")
+	args = append(args, internal.StatusCreated, internal.StatusCanceled, internal.StatusMissed, internal.ScheduleOccurrence)
+	rows, err := r.db.QueryContext(ctx, "SELECT reminder_id, COUNT(*) FROM schedules WHERE reminder_id IN ("+placeholders+") AND status NOT IN (?, ?, ?) AND kind = ? GROUP BY reminder_id", args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			reminderID int64
+			fired      int
+		)
+		if err := rows.Scan(&reminderID, &fired); err != nil {
+			return err
+		}
+		reminders[index[reminderID]].Fired = fired
+	}
+	return rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanReminder(row scanner) (*internal.Reminder, error) {
+	var (
+		reminder      internal.Reminder
+		endTime       sql.NullTime
+		repeatHourly  sql.NullString
+		repeatDaily   sql.NullString
+		repeatMonthly sql.NullInt64
+		repeatYearly  sql.NullString
+		cron          sql.NullString
+		rrule         sql.NullString
+		timezone      sql.NullString
+		calendar      sql.NullString
+		holidayPolicy sql.NullString
+		maxOccurrence sql.NullInt64
+		misfirePolicy sql.NullString
+		escalationID  sql.NullInt64
+		recipients    sql.NullString
+		webhookURL    sql.NullString
+		webhookSecret sql.NullString
+	)
+
+	err := row.Scan(
+		&reminder.ID,
+		&reminder.TaskID,
+		&reminder.StartTime,
+		&endTime,
+		&repeatHourly,
+		&repeatDaily,
+		&repeatMonthly,
+		&repeatYearly,
+		&cron,
+		&rrule,
+		&timezone,
+		&calendar,
+		&holidayPolicy,
+		&maxOccurrence,
+		&misfirePolicy,
+		&escalationID,
+		&recipients,
+		&reminder.Channel,
+		&webhookURL,
+		&webhookSecret,
+		&reminder.CreatedAt,
+		&reminder.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	reminder.EndTime = endTime.Time
+	reminder.RepeatHourly = repeatHourly.String
+	reminder.RepeatMonthly = int(repeatMonthly.Int64)
+	reminder.RepeatYearly = repeatYearly.String
+	reminder.Cron = cron.String
+	reminder.RRule = rrule.String
+	reminder.Timezone = timezone.String
+	reminder.Calendar = calendar.String
+	reminder.HolidayPolicy = holidayPolicy.String
+	reminder.MaxOccurrences = int(maxOccurrence.Int64)
+	reminder.MisfirePolicy = misfirePolicy.String
+	reminder.EscalationPolicyID = escalationID.Int64
+	reminder.WebhookURL = webhookURL.String
+	reminder.WebhookSecret = webhookSecret.String
+	if repeatDaily.String != "" {
+		if err := json.Unmarshal([]byte(repeatDaily.String), &reminder.RepeatDaily); err != nil {
+			return nil, fmt.Errorf("invalid repeat daily of reminder %d: %v", reminder.ID, err)
+		}
+	}
+	if recipients.String != "" {
+		if err := json.Unmarshal([]byte(recipients.String), &reminder.Recipients); err != nil {
+			return nil, fmt.Errorf("invalid recipients of reminder %d: %v", reminder.ID, err)
+		}
+	}
+
+	if err := reminder.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid stored reminder %d: %v", reminder.ID, err)
+	}
+
+	return &reminder, nil
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+func nullInt64(i int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: i, Valid: i != 0}
+}
+
+func expectAffected(res sql.Result, entity string, id int64) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("%s %d %w", entity, id, internal.ErrNotFound)
+	}
+
+	return nil
+}
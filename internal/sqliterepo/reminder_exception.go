@@ -0,0 +1,161 @@
+package sqliterepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/elangreza/scheduler/internal"
+	"github.com/mattn/go-sqlite3"
+)
+
+const reminderExceptionColumns = "id, reminder_id, kind, date, occurrence_at, run_at, created_at"
+
+// CreateReminderException stores an exception of a reminder. Schedules of the
+// reminder waiting for their first delivery are removed with it, so the
+// dispatcher materializes them again with the exception applied.
+func (r *reminderRepository) CreateReminderException(ctx context.Context, exception internal.ReminderException) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "INSERT INTO reminder_exceptions (reminder_id, kind, date, occurrence_at, run_at) VALUES (?, ?, ?, ?, ?)",
+		exception.ReminderID,
+		exception.Kind,
+		sql.NullString{String: exception.Date, Valid: exception.Date != ""},
+		nullTime(exception.OccurrenceAt.UTC()),
+		nullTime(exception.RunAt.UTC()),
+	)
+	if err != nil {
+		var sqliteErr sqlite3.Error
+		if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintForeignKey {
+			return 0, fmt.Errorf("reminder %d %w", exception.ReminderID, internal.ErrNotFound)
+		}
+		return 0, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := deletePendingSchedules(ctx, tx, exception.ReminderID); err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+func (r *reminderRepository) ListReminderExceptions(ctx context.Context, reminderID int64) ([]internal.ReminderException, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+reminderExceptionColumns+" FROM reminder_exceptions WHERE reminder_id = ? ORDER BY id", reminderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var exceptions []internal.ReminderException
+	for rows.Next() {
+		exception, err := scanReminderException(rows)
+		if err != nil {
+			return nil, err
+		}
+		exceptions = append(exceptions, *exception)
+	}
+	return exceptions, rows.Err()
+}
+
+// DeleteReminderException removes an exception of a reminder, and like
+// CreateReminderException the schedules waiting for their first delivery
+func (r *reminderRepository) DeleteReminderException(ctx context.Context, reminderID, id int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "DELETE FROM reminder_exceptions WHERE id = ? AND reminder_id = ?", id, reminderID)
+	if err != nil {
+		return err
+	}
+
+	if err := expectAffected(res, "reminder exception", id); err != nil {
+		return err
+	}
+
+	if err := deletePendingSchedules(ctx, tx, reminderID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// attachExceptions loads the exceptions of reminders into their Exceptions
+func (r *reminderRepository) attachExceptions(ctx context.Context, reminders []internal.Reminder) error {
+	if len(reminders) == 0 {
+		return nil
+	}
+
+	index := make(map[int64]int, len(reminders))
+	args := make([]any, 0, len(reminders))
+	for i, reminder := range reminders {
+		index[reminder.ID] = i
+		args = append(args, reminder.ID)
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")
+	rows, err := r.db.QueryContext(ctx, "SELECT "+reminderExceptionColumns+" FROM reminder_exceptions WHERE reminder_id IN ("+placeholders+") ORDER BY id", args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		exception, err := scanReminderException(rows)
+		if err != nil {
+			return err
+		}
+		i := index[exception.ReminderID]
+		reminders[i].Exceptions = append(reminders[i].Exceptions, *exception)
+	}
+	return rows.Err()
+}
+
+// deletePendingSchedules removes the schedules of a reminder that were not
+// delivered yet. A schedule claimed by the dispatcher meanwhile is no longer
+// pending and is kept.
+func deletePendingSchedules(ctx context.Context, tx *sql.Tx, reminderID int64) error {
+	_, err := tx.ExecContext(ctx, "DELETE FROM schedules WHERE reminder_id = ? AND status = ?", reminderID, internal.StatusCreated)
+	return err
+}
+
+func scanReminderException(row scanner) (*internal.ReminderException, error) {
+	var (
+		exception    internal.ReminderException
+		date         sql.NullString
+		occurrenceAt sql.NullTime
+		runAt        sql.NullTime
+	)
+
+	err := row.Scan(
+		&exception.ID,
+		&exception.ReminderID,
+		&exception.Kind,
+		&date,
+		&occurrenceAt,
+		&runAt,
+		&exception.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	exception.Date = date.String
+	exception.OccurrenceAt = occurrenceAt.Time
+	exception.RunAt = runAt.Time
+
+	return &exception, nil
+}
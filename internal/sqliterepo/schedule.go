@@ -0,0 +1,440 @@
+package sqliterepo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/elangreza/scheduler/internal"
+)
+
+const scheduleColumns = "id, task_id, reminder_id, status, notify_at, occurrence_at, done_at, is_done, response_status, attempts, last_error, retry_at, misfire, missed, kind, parent_id, step, recipients, response, responded_at, created_at, updated_at"
+
+type scheduleRepository struct {
+	db *sql.DB
+}
+
+func NewScheduleRepository(db *sql.DB) *scheduleRepository {
+	return &scheduleRepository{
+		db: db,
+	}
+}
+
+func (r *scheduleRepository) CreateSchedule(ctx context.Context, schedule internal.Schedule) (int64, error) {
+	return insertSchedule(ctx, r.db, schedule)
+}
+
+func (r *scheduleRepository) GetSchedule(ctx context.Context, id int64) (*internal.Schedule, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+scheduleColumns+" FROM schedules WHERE id = ?", id)
+
+	schedule, err := scanSchedule(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("schedule %d %w", id, internal.ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+// GetLastSchedule returns the schedule of a reminder with the latest
+// occurrence, snoozes are not materialized from occurrences and are left out
+func (r *scheduleRepository) GetLastSchedule(ctx context.Context, reminderID int64) (*internal.Schedule, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+scheduleColumns+" FROM schedules WHERE reminder_id = ? AND kind = ? ORDER BY occurrence_at DESC, id DESC LIMIT 1", reminderID, internal.ScheduleOccurrence)
+
+	schedule, err := scanSchedule(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("schedule of reminder %d %w", reminderID, internal.ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+// ListDueSchedules returns created schedules whose notify time is not after
+// now, failed schedules whose retry time is not after now, and sending
+// schedules whose lease expired
+func (r *scheduleRepository) ListDueSchedules(ctx context.Context, now time.Time, limit int) ([]internal.Schedule, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+scheduleColumns+" FROM schedules WHERE (status = ? AND notify_at <= ?) OR (status IN (?, ?) AND retry_at <= ?) ORDER BY COALESCE(retry_at, notify_at), id LIMIT ?",
+		internal.StatusCreated,
+		now.UTC(),
+		internal.StatusSending,
+		internal.StatusFailed,
+		now.UTC(),
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []internal.Schedule
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, *schedule)
+	}
+	return schedules, rows.Err()
+}
+
+// ClaimSchedule marks a due schedule as sending until leaseUntil, its retry
+// time while it is sending. It returns false when the schedule is no longer
+// due, which means another worker already took it.
+func (r *scheduleRepository) ClaimSchedule(ctx context.Context, id int64, now, leaseUntil time.Time) (bool, error) {
+	res, err := r.db.ExecContext(ctx, "UPDATE schedules SET status = ?, retry_at = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND ((status = ? AND notify_at <= ?) OR (status IN (?, ?) AND retry_at <= ?))",
+		internal.StatusSending,
+		leaseUntil.UTC(),
+		id,
+		internal.StatusCreated,
+		now.UTC(),
+		internal.StatusSending,
+		internal.StatusFailed,
+		now.UTC(),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// FinishSchedule stores the final status and delivery result of a schedule
+// and marks it as done
+func (r *scheduleRepository) FinishSchedule(ctx context.Context, schedule internal.Schedule) error {
+	return finishSchedule(ctx, r.db, schedule)
+}
+
+// OutboxSchedule finishes a delivered schedule and writes its notifications
+// to the outbox in the same transaction. Entries whose dedupe key is already
+// in the outbox are left out.
+func (r *scheduleRepository) OutboxSchedule(ctx context.Context, schedule internal.Schedule, entries []internal.OutboxEntry) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := finishSchedule(ctx, tx, schedule); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		_, err := tx.ExecContext(ctx, "INSERT INTO outbox (schedule_id, dedupe_key, payload, status, next_attempt_at) VALUES (?, ?, ?, ?, ?) ON CONFLICT (dedupe_key) DO NOTHING",
+			entry.ScheduleID,
+			entry.DedupeKey,
+			string(entry.Payload),
+			entry.Status,
+			entry.NextAttemptAt.UTC(),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func finishSchedule(ctx context.Context, db execer, schedule internal.Schedule) error {
+	res, err := db.ExecContext(ctx, "UPDATE schedules SET status = ?, done_at = ?, is_done = 1, response_status = ?, attempts = ?, last_error = ?, retry_at = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
+		schedule.Status,
+		schedule.DoneAt.UTC(),
+		schedule.ResponseStatus,
+		schedule.Attempts,
+		schedule.LastError,
+		schedule.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(res, "schedule", schedule.ID)
+}
+
+// MisfireSchedule stores the misfire decision of a claimed schedule, before
+// it is delivered or finished as missed
+func (r *scheduleRepository) MisfireSchedule(ctx context.Context, schedule internal.Schedule) error {
+	res, err := r.db.ExecContext(ctx, "UPDATE schedules SET occurrence_at = ?, misfire = ?, missed = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
+		schedule.OccurrenceAt.UTC(),
+		schedule.Misfire,
+		schedule.Missed,
+		schedule.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(res, "schedule", schedule.ID)
+}
+
+// RespondSchedule stores the response of the recipient to a delivered
+// schedule. It fails with ErrConflict when the schedule was responded to
+// meanwhile.
+func (r *scheduleRepository) RespondSchedule(ctx context.Context, schedule internal.Schedule) error {
+	return respondSchedule(ctx, r.db, schedule)
+}
+
+// SnoozeSchedule stores the response of a snoozed schedule and creates the
+// snooze firing it again, it returns the id of the snooze
+func (r *scheduleRepository) SnoozeSchedule(ctx context.Context, schedule, snooze internal.Schedule) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := respondSchedule(ctx, tx, schedule); err != nil {
+		return 0, err
+	}
+
+	id, err := insertSchedule(ctx, tx, snooze)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+// EscalationResponded reports whether the recipients responded to the
+// escalated schedule, or acknowledged one of its escalations or deferred
+// deliveries, which ends its escalation chain
+func (r *scheduleRepository) EscalationResponded(ctx context.Context, parentID int64) (bool, error) {
+	var responded bool
+	err := r.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM schedules WHERE (id = ? OR (parent_id = ? AND kind IN (?, ?))) AND response IS NOT NULL)",
+		parentID,
+		parentID,
+		internal.ScheduleEscalation,
+		internal.ScheduleDeferred,
+	).Scan(&responded)
+	return responded, err
+}
+
+// QuietSchedule stores the recipients quiet hours left to a claimed schedule,
+// and the deferred schedules delivering it to the others. A deferred schedule
+// is merged into the pending one of the reminder deferred to the same time,
+// so a reminder firing again and again during quiet hours notifies once at
+// their end.
+func (r *scheduleRepository) QuietSchedule(ctx context.Context, schedule internal.Schedule, deferred []internal.Schedule) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	recipients, err := nullRecipients(schedule.Recipients)
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, "UPDATE schedules SET recipients = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?", recipients, schedule.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := expectAffected(res, "schedule", schedule.ID); err != nil {
+		return err
+	}
+
+	for _, d := range deferred {
+		if err := mergeDeferredSchedule(ctx, tx, d); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func mergeDeferredSchedule(ctx context.Context, tx *sql.Tx, deferred internal.Schedule) error {
+	var (
+		id         int64
+		recipients sql.NullString
+	)
+	err := tx.QueryRowContext(ctx, "SELECT id, recipients FROM schedules WHERE reminder_id = ? AND kind = ? AND status = ? AND notify_at = ? AND step = ? ORDER BY id LIMIT 1",
+		deferred.ReminderID,
+		internal.ScheduleDeferred,
+		internal.StatusCreated,
+		deferred.NotifyAt.UTC(),
+		deferred.Step,
+	).Scan(&id, &recipients)
+	if errors.Is(err, sql.ErrNoRows) {
+		_, err = insertSchedule(ctx, tx, deferred)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	var merged []string
+	if recipients.String != "" {
+		if err := json.Unmarshal([]byte(recipients.String), &merged); err != nil {
+			return fmt.Errorf("invalid recipients of schedule %d: %v", id, err)
+		}
+	}
+	for _, recipient := range deferred.Recipients {
+		if !slices.Contains(merged, recipient) {
+			merged = append(merged, recipient)
+		}
+	}
+
+	value, err := nullRecipients(merged)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE schedules SET recipients = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?", value, id)
+	return err
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func insertSchedule(ctx context.Context, db execer, schedule internal.Schedule) (int64, error) {
+	recipients, err := nullRecipients(schedule.Recipients)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := db.ExecContext(ctx, "INSERT INTO schedules (task_id, reminder_id, status, notify_at, occurrence_at, is_done, kind, parent_id, step, recipients) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		schedule.TaskID,
+		schedule.ReminderID,
+		schedule.Status,
+		schedule.NotifyAt.UTC(),
+		schedule.OccurrenceAt.UTC(),
+		schedule.IsDone,
+		schedule.Kind,
+		nullInt64(schedule.ParentID),
+		schedule.Step,
+		recipients,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// nullRecipients encodes the recipients of a schedule, NULL for the
+// recipients of its reminder
+func nullRecipients(recipients []string) (sql.NullString, error) {
+	if len(recipients) == 0 {
+		return sql.NullString{}, nil
+	}
+
+	value, err := json.Marshal(recipients)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(value), Valid: true}, nil
+}
+
+func respondSchedule(ctx context.Context, db execer, schedule internal.Schedule) error {
+	res, err := db.ExecContext(ctx, "UPDATE schedules SET response = ?, responded_at = ?, is_done = 1, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND status = ? AND response IS NULL",
+		schedule.Response,
+		schedule.RespondedAt.UTC(),
+		schedule.ID,
+		internal.StatusSuccess,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("schedule %d %w", schedule.ID, internal.ErrConflict)
+	}
+
+	return nil
+}
+
+// RetrySchedule stores a failed delivery of a schedule that will be attempted
+// again at its retry time
+func (r *scheduleRepository) RetrySchedule(ctx context.Context, schedule internal.Schedule) error {
+	res, err := r.db.ExecContext(ctx, "UPDATE schedules SET status = ?, response_status = ?, attempts = ?, last_error = ?, retry_at = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
+		internal.StatusFailed,
+		schedule.ResponseStatus,
+		schedule.Attempts,
+		schedule.LastError,
+		schedule.RetryAt.UTC(),
+		schedule.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(res, "schedule", schedule.ID)
+}
+
+func scanSchedule(row scanner) (*internal.Schedule, error) {
+	var (
+		schedule    internal.Schedule
+		doneAt      sql.NullTime
+		lastError   sql.NullString
+		retryAt     sql.NullTime
+		misfire     sql.NullString
+		parentID    sql.NullInt64
+		recipients  sql.NullString
+		response    sql.NullString
+		respondedAt sql.NullTime
+	)
+
+	err := row.Scan(
+		&schedule.ID,
+		&schedule.TaskID,
+		&schedule.ReminderID,
+		&schedule.Status,
+		&schedule.NotifyAt,
+		&schedule.OccurrenceAt,
+		&doneAt,
+		&schedule.IsDone,
+		&schedule.ResponseStatus,
+		&schedule.Attempts,
+		&lastError,
+		&retryAt,
+		&misfire,
+		&schedule.Missed,
+		&schedule.Kind,
+		&parentID,
+		&schedule.Step,
+		&recipients,
+		&response,
+		&respondedAt,
+		&schedule.CreatedAt,
+		&schedule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule.DoneAt = doneAt.Time
+	schedule.LastError = lastError.String
+	schedule.RetryAt = retryAt.Time
+	schedule.Misfire = misfire.String
+	schedule.ParentID = parentID.Int64
+	schedule.Response = response.String
+	schedule.RespondedAt = respondedAt.Time
+	if recipients.String != "" {
+		if err := json.Unmarshal([]byte(recipients.String), &schedule.Recipients); err != nil {
+			return nil, fmt.Errorf("invalid recipients of schedule %d: %v", schedule.ID, err)
+		}
+	}
+
+	return &schedule, nil
+}
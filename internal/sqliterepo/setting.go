@@ -0,0 +1,32 @@
+package sqliterepo
+
+import (
+	"context"
+	"database/sql"
+)
+
+type settingRepository struct {
+	db *sql.DB
+}
+
+func NewSettingRepository(db *sql.DB) *settingRepository {
+	return &settingRepository{
+		db: db,
+	}
+}
+
+// GetOrCreateSetting returns the value of the setting, storing value first
+// when the setting does not exist yet
+func (r *settingRepository) GetOrCreateSetting(ctx context.Context, key, value string) (string, error) {
+	_, err := r.db.ExecContext(ctx, "INSERT INTO settings (key, value) VALUES (?, ?) ON CONFLICT (key) DO NOTHING", key, value)
+	if err != nil {
+		return "", err
+	}
+
+	var stored string
+	if err := r.db.QueryRowContext(ctx, "SELECT value FROM settings WHERE key = ?", key).Scan(&stored); err != nil {
+		return "", err
+	}
+
+	return stored, nil
+}
@@ -8,7 +8,8 @@ import (
 
 func NewSql(fileName string) (*sql.DB, error) {
 	// change using sqlite
-	db, err := sql.Open("sqlite3", fileName)
+	// foreign keys are disabled by default in sqlite, reminders rely on them for cascade delete
+	db, err := sql.Open("sqlite3", fileName+"?_foreign_keys=on")
 	if err != nil {
 		return nil, err
 	}
@@ -3,6 +3,8 @@ package sqliterepo
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 
 	"github.com/elangreza/scheduler/internal"
 )
@@ -18,15 +20,16 @@ func NewTaskRepository(db *sql.DB) *taskRepository {
 }
 
 func (r *taskRepository) CreateTask(ctx context.Context, task internal.Task) error {
-	_, err := r.db.ExecContext(ctx, "INSERT INTO tasks (name, description) VALUES (?, ?)",
+	_, err := r.db.ExecContext(ctx, "INSERT INTO tasks (name, description, email_template) VALUES (?, ?, ?)",
 		task.Name,
 		task.Description,
+		task.EmailTemplate,
 	)
 	return err
 }
 
 func (r *taskRepository) ListTasks(ctx context.Context) ([]internal.Task, error) {
-	rows, err := r.db.QueryContext(ctx, "SELECT id, name, description FROM tasks")
+	rows, err := r.db.QueryContext(ctx, "SELECT id, name, description, email_template FROM tasks")
 	if err != nil {
 		return nil, err
 	}
@@ -35,7 +38,7 @@ func (r *taskRepository) ListTasks(ctx context.Context) ([]internal.Task, error)
 	var tasks []internal.Task
 	for rows.Next() {
 		var task internal.Task
-		if err := rows.Scan(&task.ID, &task.Name, &task.Description); err != nil {
+		if err := rows.Scan(&task.ID, &task.Name, &task.Description, &task.EmailTemplate); err != nil {
 			return nil, err
 		}
 		tasks = append(tasks, task)
@@ -43,15 +46,29 @@ func (r *taskRepository) ListTasks(ctx context.Context) ([]internal.Task, error)
 	return tasks, nil
 }
 
+func (r *taskRepository) GetTask(ctx context.Context, id int64) (*internal.Task, error) {
+	var task internal.Task
+	err := r.db.QueryRowContext(ctx, "SELECT id, name, description, email_template FROM tasks WHERE id = ?", id).
+		Scan(&task.ID, &task.Name, &task.Description, &task.EmailTemplate)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("task %d %w", id, internal.ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
 func (r *taskRepository) DeleteTask(ctx context.Context, id int) error {
 	_, err := r.db.ExecContext(ctx, "DELETE FROM tasks WHERE id = ?", id)
 	return err
 }
 
 func (r *taskRepository) UpdateTask(ctx context.Context, id int, req internal.UpdateTaskParams) error {
-	_, err := r.db.ExecContext(ctx, "UPDATE tasks SET name = ?, description = ? WHERE id = ?",
+	_, err := r.db.ExecContext(ctx, "UPDATE tasks SET name = ?, description = ?, email_template = ? WHERE id = ?",
 		req.Name,
 		req.Description,
+		req.EmailTemplate,
 		id,
 	)
 	return err
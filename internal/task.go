@@ -21,17 +21,21 @@ type (
 		ID          int64  `json:"id"`
 		Name        string `json:"name"`
 		Description string `json:"description"` // optional, can be nil
+		// EmailTemplate names the email template set overriding the default
+		// email templates for the task, empty for the default ones
+		EmailTemplate string `json:"email_template"`
 
 		CreatedAt time.Time `json:"created_at"`
 		UpdatedAt time.Time `json:"updated_at"`
 	}
 )
 
-func NewTask(name, description string) (*Task, error) {
+func NewTask(name, description, emailTemplate string) (*Task, error) {
 
 	task := &Task{
-		Name:        name,
-		Description: description,
+		Name:          name,
+		Description:   description,
+		EmailTemplate: emailTemplate,
 	}
 
 	if task.Name == "" {
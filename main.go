@@ -1,18 +1,25 @@
 package main
 
 import (
-	"database/sql"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"strconv"
 
 	"github.com/elangreza/scheduler/config"
+	"github.com/elangreza/scheduler/internal"
+	"github.com/elangreza/scheduler/internal/calendar"
+	"github.com/elangreza/scheduler/internal/emailtemplate"
+	"github.com/elangreza/scheduler/internal/mailer"
+	"github.com/elangreza/scheduler/internal/notifier"
 	"github.com/elangreza/scheduler/internal/rest"
 	"github.com/elangreza/scheduler/internal/service"
+	"github.com/elangreza/scheduler/internal/smtpsink"
 	"github.com/elangreza/scheduler/internal/sqliterepo"
-	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/sqlite"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
 func main() {
@@ -39,13 +46,92 @@ func main() {
 		}
 	}()
 
-	if err := migrateDB(db); err != nil {
+	if err := sqliterepo.Migrate(db, "./migrations"); err != nil {
 		log.Fatal(err)
 	}
 
 	taskRepo := sqliterepo.NewTaskRepository(db)
-	schedulerService := service.NewTaskService(taskRepo)
-	handler := rest.NewHandler(schedulerService)
+	reminderRepo := sqliterepo.NewReminderRepository(db)
+	calendars, err := calendar.LoadDir(cfg.CalendarDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	emailTemplates, err := emailtemplate.LoadDir(cfg.EmailTemplateDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	scheduleRepo := sqliterepo.NewScheduleRepository(db)
+	escalationRepo := sqliterepo.NewEscalationRepository(db)
+	quietHoursRepo := sqliterepo.NewQuietHoursRepository(db)
+	clock := internal.SystemClock{}
+	schedulerService := service.NewTaskService(taskRepo, reminderRepo, scheduleRepo, escalationRepo, quietHoursRepo, calendars, emailTemplates, clock)
+	actionSecret := cfg.ActionSecret
+	if actionSecret == "" {
+		// the generated secret is kept in the database, links of
+		// notifications keep working after a restart
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			log.Fatal(err)
+		}
+		actionSecret, err = sqliterepo.NewSettingRepository(db).GetOrCreateSetting(context.Background(), "action_secret", hex.EncodeToString(secret))
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	links := internal.NewActionLinks(cfg.BaseURL, actionSecret, cfg.ActionLinkTTL, clock)
+	handler := rest.NewHandler(schedulerService, links)
+
+	var devMail *smtpsink.Sink
+	if cfg.DevMail {
+		devMail, err = smtpsink.Listen(net.JoinHostPort("127.0.0.1", strconv.Itoa(cfg.DevMailPort)), clock)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer devMail.Close()
+
+		cfg.SmtpHost = "127.0.0.1"
+		cfg.SmtpPort = devMail.Addr().Port
+		cfg.SmtpTLS = mailer.TLSNone
+		cfg.SmtpAuth = mailer.AuthNone
+		if cfg.SmtpFrom == "" && cfg.SmtpAuthEmail == "" {
+			cfg.SmtpFrom = "Scheduler <scheduler@localhost>"
+		}
+		log.Printf("DEV_MAIL is set, emails are captured by the SMTP sink at %s and shown at http://localhost:8080/dev/mail", devMail.Addr())
+	}
+
+	emailMailer, err := mailer.NewMailer(cfg, clock)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer emailMailer.Close()
+
+	notifiers := notifier.NewRegistry()
+	notifiers.Register(internal.ChannelEmail, notifier.NewEmail(emailMailer, emailTemplates, links, quietHoursRepo, cfg.EmailAttachICS))
+	notifiers.Register(internal.ChannelWebhook, notifier.NewWebhook(nil, clock))
+	notifiers.Register(internal.ChannelLog, notifier.NewLog(os.Stdout))
+
+	retryPolicy := internal.RetryPolicy{
+		MaxAttempts: cfg.RetryMaxAttempts,
+		BaseDelay:   cfg.RetryBaseDelay,
+		MaxDelay:    cfg.RetryMaxDelay,
+		Jitter:      cfg.RetryJitter,
+	}
+	var quietHours *internal.QuietHours
+	if cfg.QuietHoursStart != "" || cfg.QuietHoursEnd != "" {
+		quietHours, err = internal.NewQuietHours("", cfg.QuietHoursStart, cfg.QuietHoursEnd, cfg.QuietHoursTimezone, cfg.QuietHoursPolicy)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	relay := service.NewRelay(sqliterepo.NewOutboxRepository(db), emailMailer, retryPolicy, cfg.OutboxRelayInterval, clock)
+	dispatcher := service.NewDispatcher(taskRepo, reminderRepo, scheduleRepo, quietHoursRepo, notifiers, relay, calendars, retryPolicy, quietHours, cfg.DispatchInterval, cfg.MisfireThreshold, clock)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go dispatcher.Run(ctx)
+	go relay.Run(ctx)
 
 	http.HandleFunc("/", handler.RootHandler)
 	http.HandleFunc("/tasks", func(w http.ResponseWriter, r *http.Request) {
@@ -63,19 +149,197 @@ func main() {
 		}
 	})
 
-	log.Println("Server started at http://localhost:8080/")
-	http.ListenAndServe(":8080", nil)
-
-	// to := []string{"babehracing14@gmail.com"}
-	// cc := []string{}
-	// subject := "Test mail"
-	// message := "Hello"
+	http.HandleFunc("/tasks/{id}/reminders", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handler.ListReminderHandler(w, r)
+		case http.MethodPost:
+			handler.CreateReminderHandler(w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	http.HandleFunc("/reminders/{id}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handler.GetReminderHandler(w, r)
+		case http.MethodPut, http.MethodPatch:
+			handler.UpdateReminderHandler(w, r)
+		case http.MethodDelete:
+			handler.DeleteReminderHandler(w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	http.HandleFunc("/schedules/{id}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handler.GetScheduleHandler(w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	http.HandleFunc("/schedules/{id}/acknowledge", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handler.AcknowledgeLinkHandler(w, r)
+		case http.MethodPost:
+			handler.AcknowledgeScheduleHandler(w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	http.HandleFunc("/schedules/{id}/snooze", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handler.SnoozeLinkHandler(w, r)
+		case http.MethodPost:
+			handler.SnoozeScheduleHandler(w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	http.HandleFunc("/schedules/{id}/acknowledge/confirm", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			handler.ConfirmAcknowledgeHandler(w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	http.HandleFunc("/schedules/{id}/snooze/confirm", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			handler.ConfirmSnoozeHandler(w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	http.HandleFunc("/escalation-policies", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handler.ListEscalationPoliciesHandler(w, r)
+		case http.MethodPost:
+			handler.CreateEscalationPolicyHandler(w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	http.HandleFunc("/escalation-policies/{id}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handler.GetEscalationPolicyHandler(w, r)
+		case http.MethodPut, http.MethodPatch:
+			handler.UpdateEscalationPolicyHandler(w, r)
+		case http.MethodDelete:
+			handler.DeleteEscalationPolicyHandler(w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	http.HandleFunc("/quiet-hours", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handler.ListQuietHoursHandler(w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	http.HandleFunc("/quiet-hours/{recipient}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handler.GetQuietHoursHandler(w, r)
+		case http.MethodPut:
+			handler.PutQuietHoursHandler(w, r)
+		case http.MethodDelete:
+			handler.DeleteQuietHoursHandler(w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	http.HandleFunc("/calendars", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handler.ListCalendarsHandler(w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	http.HandleFunc("/reminders/{id}/exceptions", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handler.ListReminderExceptionsHandler(w, r)
+		case http.MethodPost:
+			handler.CreateReminderExceptionHandler(w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	http.HandleFunc("/reminders/{id}/exceptions/{exceptionID}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodDelete:
+			handler.DeleteReminderExceptionHandler(w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	http.HandleFunc("/reminders/{id}/occurrences", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handler.ListOccurrencesHandler(w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	http.HandleFunc("/reminders/occurrences", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			handler.PreviewOccurrencesHandler(w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
 
-	// err = sendMail(cfg, to, cc, subject, message)
-	// if err != nil {
-	// 	log.Fatal(err.Error())
-	// }
+	if devMail != nil {
+		devMailHandler := rest.NewDevMailHandler(devMail)
+		http.HandleFunc("/dev/mail", func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				devMailHandler.PageHandler(w, r)
+			default:
+				http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			}
+		})
+		http.HandleFunc("/dev/mail/messages", func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				devMailHandler.ListMessagesHandler(w, r)
+			case http.MethodDelete:
+				devMailHandler.ClearMessagesHandler(w, r)
+			default:
+				http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			}
+		})
+		http.HandleFunc("/dev/mail/messages/{id}", func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				devMailHandler.GetMessageHandler(w, r)
+			default:
+				http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			}
+		})
+		http.HandleFunc("/dev/mail/messages/{id}/raw", func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				devMailHandler.RawMessageHandler(w, r)
+			default:
+				http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			}
+		})
+	}
 
+	log.Println("Server started at http://localhost:8080/")
+	http.ListenAndServe(":8080", nil)
 }
 
 func fileExists(filename string) bool {
@@ -92,27 +356,3 @@ func createDBFile(filename string) error {
 
 	return nil
 }
-
-func migrateDB(db *sql.DB) error {
-	driver, err := sqlite.WithInstance(db, &sqlite.Config{})
-	if err != nil {
-		log.Fatal(err)
-	}
-	migrationsPath := "file://./migrations"
-	if _, err := os.Stat("./migrations"); os.IsNotExist(err) {
-		return err
-	}
-	m, err := migrate.NewWithDatabaseInstance(
-		migrationsPath,
-		"sqlite3", driver)
-	if err != nil {
-		return err
-	}
-
-	err = m.Up()
-	if err != nil && err != migrate.ErrNoChange {
-		return err
-	}
-
-	return nil
-}
@@ -0,0 +1 @@
+ALTER TABLE reminders ADD COLUMN timezone TEXT NULL;
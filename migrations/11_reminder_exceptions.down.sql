@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_schedules_reminder_id_occurrence_at;
+ALTER TABLE schedules DROP COLUMN occurrence_at;
+
+DROP INDEX IF EXISTS idx_reminder_exceptions_reminder_id;
+DROP TABLE IF EXISTS reminder_exceptions;
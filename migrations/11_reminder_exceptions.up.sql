@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS reminder_exceptions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    reminder_id INTEGER NOT NULL,
+    kind TEXT NOT NULL,
+    date TEXT NULL,
+    occurrence_at TIMESTAMP NULL,
+    run_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (reminder_id) REFERENCES reminders(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_reminder_exceptions_reminder_id ON reminder_exceptions(reminder_id);
+
+ALTER TABLE schedules ADD COLUMN occurrence_at TIMESTAMP NULL;
+UPDATE schedules SET occurrence_at = notify_at;
+
+CREATE INDEX IF NOT EXISTS idx_schedules_reminder_id_occurrence_at ON schedules(reminder_id, occurrence_at);
@@ -0,0 +1,2 @@
+ALTER TABLE reminders DROP COLUMN holiday_policy;
+ALTER TABLE reminders DROP COLUMN calendar;
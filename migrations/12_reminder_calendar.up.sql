@@ -0,0 +1,2 @@
+ALTER TABLE reminders ADD COLUMN calendar TEXT NULL;
+ALTER TABLE reminders ADD COLUMN holiday_policy TEXT NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE reminders DROP COLUMN repeat_yearly;
+ALTER TABLE reminders DROP COLUMN repeat_monthly;
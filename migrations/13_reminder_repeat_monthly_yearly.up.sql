@@ -0,0 +1,2 @@
+ALTER TABLE reminders ADD COLUMN repeat_monthly INTEGER NULL;
+ALTER TABLE reminders ADD COLUMN repeat_yearly TEXT NULL;
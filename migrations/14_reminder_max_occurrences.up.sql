@@ -0,0 +1 @@
+ALTER TABLE reminders ADD COLUMN max_occurrences INTEGER NULL;
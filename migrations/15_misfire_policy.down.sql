@@ -0,0 +1,4 @@
+ALTER TABLE schedules DROP COLUMN missed;
+ALTER TABLE schedules DROP COLUMN misfire;
+
+ALTER TABLE reminders DROP COLUMN misfire_policy;
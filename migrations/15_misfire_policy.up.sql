@@ -0,0 +1,4 @@
+ALTER TABLE reminders ADD COLUMN misfire_policy TEXT NULL;
+
+ALTER TABLE schedules ADD COLUMN misfire TEXT NULL;
+ALTER TABLE schedules ADD COLUMN missed INTEGER NOT NULL DEFAULT 0;
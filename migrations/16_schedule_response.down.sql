@@ -0,0 +1,4 @@
+ALTER TABLE schedules DROP COLUMN responded_at;
+ALTER TABLE schedules DROP COLUMN response;
+ALTER TABLE schedules DROP COLUMN parent_id;
+ALTER TABLE schedules DROP COLUMN kind;
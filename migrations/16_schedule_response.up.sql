@@ -0,0 +1,4 @@
+ALTER TABLE schedules ADD COLUMN kind TEXT NOT NULL DEFAULT 'occurrence';
+ALTER TABLE schedules ADD COLUMN parent_id INTEGER NULL REFERENCES schedules(id) ON DELETE CASCADE;
+ALTER TABLE schedules ADD COLUMN response TEXT NULL;
+ALTER TABLE schedules ADD COLUMN responded_at TIMESTAMP NULL;
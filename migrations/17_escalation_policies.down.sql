@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_schedules_parent_id;
+ALTER TABLE schedules DROP COLUMN step;
+ALTER TABLE reminders DROP COLUMN escalation_policy_id;
+DROP TABLE IF EXISTS escalation_policies;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS escalation_policies (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    steps TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE reminders ADD COLUMN escalation_policy_id INTEGER NULL REFERENCES escalation_policies(id) ON DELETE SET NULL;
+
+ALTER TABLE schedules ADD COLUMN step INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_schedules_parent_id ON schedules(parent_id);
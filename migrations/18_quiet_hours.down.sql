@@ -0,0 +1,2 @@
+ALTER TABLE schedules DROP COLUMN recipients;
+DROP TABLE IF EXISTS quiet_hours;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS quiet_hours (
+    recipient TEXT PRIMARY KEY,
+    start_time TEXT NOT NULL,
+    end_time TEXT NOT NULL,
+    timezone TEXT NOT NULL DEFAULT '',
+    policy TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE schedules ADD COLUMN recipients TEXT NULL;
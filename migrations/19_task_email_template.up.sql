@@ -0,0 +1 @@
+ALTER TABLE tasks ADD COLUMN email_template TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+-- Drop table reminders if exists
+DROP TABLE IF EXISTS reminders;
\ No newline at end of file
@@ -0,0 +1,2 @@
+-- Drop table schedules if exists
+DROP TABLE IF EXISTS schedules;
\ No newline at end of file
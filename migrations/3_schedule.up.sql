@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS schedules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id INTEGER NOT NULL,
+    reminder_id INTEGER NOT NULL,
+    status INTEGER NOT NULL DEFAULT 0,
+    notify_at TIMESTAMP NOT NULL,
+    done_at TIMESTAMP NULL,
+    is_done BOOLEAN NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE,
+    FOREIGN KEY (reminder_id) REFERENCES reminders(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_schedules_reminder_id ON schedules(reminder_id);
+CREATE INDEX IF NOT EXISTS idx_schedules_status_notify_at ON schedules(status, notify_at);
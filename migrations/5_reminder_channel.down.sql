@@ -0,0 +1,2 @@
+ALTER TABLE reminders DROP COLUMN webhook_url;
+ALTER TABLE reminders DROP COLUMN channel;
@@ -0,0 +1,2 @@
+ALTER TABLE reminders ADD COLUMN channel TEXT NOT NULL DEFAULT 'email';
+ALTER TABLE reminders ADD COLUMN webhook_url TEXT NULL;
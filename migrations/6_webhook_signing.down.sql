@@ -0,0 +1,2 @@
+ALTER TABLE schedules DROP COLUMN response_status;
+ALTER TABLE reminders DROP COLUMN webhook_secret;
@@ -0,0 +1,2 @@
+ALTER TABLE reminders ADD COLUMN webhook_secret TEXT NULL;
+ALTER TABLE schedules ADD COLUMN response_status INTEGER NOT NULL DEFAULT 0;
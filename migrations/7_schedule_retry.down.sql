@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_schedules_status_retry_at;
+
+ALTER TABLE schedules DROP COLUMN retry_at;
+ALTER TABLE schedules DROP COLUMN last_error;
+ALTER TABLE schedules DROP COLUMN attempts;
@@ -0,0 +1,5 @@
+ALTER TABLE schedules ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE schedules ADD COLUMN last_error TEXT NULL;
+ALTER TABLE schedules ADD COLUMN retry_at TIMESTAMP NULL;
+
+CREATE INDEX IF NOT EXISTS idx_schedules_status_retry_at ON schedules(status, retry_at);
@@ -0,0 +1 @@
+ALTER TABLE reminders ADD COLUMN cron TEXT NULL;
@@ -0,0 +1 @@
+ALTER TABLE reminders DROP COLUMN rrule;